@@ -0,0 +1,102 @@
+package go_bcdedit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"os"
+	"strings"
+)
+
+const (
+	DefaultAlias = "{default}"
+	CurrentAlias = "{current}"
+)
+
+// maxAliasDepth bounds user aliases that point at other aliases.
+const maxAliasDepth = 8
+
+// AliasResolver turns identifiers given on input into stored object ids.
+// Well-known aliases such as {bootmgr} always resolve; {default} follows
+// the DefaultObject of {bootmgr}, {current} needs Current because it names
+// the entry the running system booted from, and Aliases holds user-defined
+// names, e.g. "{work}" -> "{2b1ef6c4-...}".
+type AliasResolver struct {
+	Aliases map[string]string
+	Current string
+}
+
+// LoadAliasConfig reads an alias config file such as
+//
+//	{"aliases": {"{work}": "{2b1ef6c4-6a1c-4d0e-9e8c-6f3d1c0b7a11}"}, "current": "{work}"}
+func LoadAliasConfig(file string) (*AliasResolver, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config model.AliasConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	resolver := &AliasResolver{
+		Aliases: make(map[string]string),
+		Current: config.Current,
+	}
+	for name, target := range config.Aliases {
+		resolver.Aliases[normalizeAlias(name)] = target
+	}
+	return resolver, nil
+}
+
+// Resolve returns the canonical object id for an alias or GUID.
+func (r *AliasResolver) Resolve(bcd Bcdedit, objectId string) (string, error) {
+	id := objectId
+	for depth := 0; depth < maxAliasDepth; depth++ {
+		alias := normalizeAlias(id)
+		if r != nil {
+			if target, ok := r.Aliases[alias]; ok {
+				id = target
+				continue
+			}
+		}
+		switch alias {
+		case DefaultAlias:
+			return resolveDefault(bcd)
+		case CurrentAlias:
+			if r == nil || r.Current == "" {
+				return "", fmt.Errorf("%s is unknown outside the running system, map it to an entry", CurrentAlias)
+			}
+			id = r.Current
+			continue
+		}
+		if known, ok := WellKnownObjectIds[alias]; ok {
+			return known, nil
+		}
+		return model.CanonicalObjectId(id)
+	}
+	return "", fmt.Errorf("alias %s nests too deeply", objectId)
+}
+
+func resolveDefault(bcd Bcdedit) (string, error) {
+	bootmgr, err := bcd.GetObject(BootMgrObjectId)
+	if err != nil {
+		return "", err
+	}
+	element, ok := bootmgr.GetElements()[model.ElementDefaultObject]
+	if !ok {
+		return "", fmt.Errorf("%s has no default entry", "{bootmgr}")
+	}
+	id, err := element.GetObject()
+	if err != nil {
+		return "", err
+	}
+	return model.CanonicalObjectId(id)
+}
+
+func normalizeAlias(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "{") {
+		name = "{" + name + "}"
+	}
+	return name
+}
@@ -0,0 +1,78 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveObjectId(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	bcd.(*HiveBcdedit).Aliases = &AliasResolver{
+		Aliases: map[string]string{
+			"{work}": testOtherId,
+			"{lab}":  "{work}",
+			"{loop}": "{loop}",
+		},
+		Current: "{lab}",
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{bootmgr}", BootMgrObjectId},
+		{"BOOTMGR", BootMgrObjectId},
+		{"{default}", testLoaderId},
+		{"{work}", testOtherId},
+		{"lab", testOtherId},
+		{"{current}", testOtherId},
+		{"{11111111-2222-4333-8444-555555555555}", testLoaderId},
+		{"11111111-2222-4333-8444-555555555555", testLoaderId},
+	}
+	for _, test := range tests {
+		if got, err := bcd.ResolveObjectId(test.input); err != nil || got != test.want {
+			t.Errorf("%s: got %s, %v, want %s", test.input, got, err, test.want)
+		}
+	}
+	for _, input := range []string{"{loop}", "{nosuchalias}"} {
+		if _, err := bcd.ResolveObjectId(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+
+	bcd.(*HiveBcdedit).Aliases = nil
+	if _, err := bcd.ResolveObjectId("{current}"); err == nil {
+		t.Error("{current} without a mapping: expected an error")
+	}
+	if err := mustGetObject(t, bcd, "{bootmgr}").DeleteElement(model.ElementDefaultObject); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bcd.ResolveObjectId("{default}"); err == nil {
+		t.Error("{default} without a default entry: expected an error")
+	}
+}
+
+func TestLoadAliasConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "aliases.json")
+	config := `{"aliases": {"Work": "` + testOtherId + `"}, "current": "{work}"}`
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := LoadAliasConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := resolver.Resolve(nil, "{current}"); err != nil || got != testOtherId {
+		t.Errorf("got %s, %v, want %s", got, err, testOtherId)
+	}
+
+	if err = os.WriteFile(file, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadAliasConfig(file); err == nil {
+		t.Error("invalid config: expected an error")
+	}
+}
@@ -0,0 +1,297 @@
+package go_bcdedit
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ApplyTypeKey is the reserved ApplySpec key holding the object type.
+const ApplyTypeKey = "type"
+
+type ApplyOptions struct {
+	// Prune deletes the elements of listed objects that the spec does not mention.
+	Prune bool
+}
+
+// objectTypes are the names accepted for ApplyTypeKey, as in bcdedit /create.
+var objectTypes = map[string]model.BcdDescription{
+	"fwbootmgr":  0x10100001,
+	"bootmgr":    0x10100002,
+	"osloader":   0x10200003,
+	"resume":     0x10200004,
+	"memdiag":    0x10200005,
+	"ntldr":      0x10300006,
+	"setupldr":   0x10300007,
+	"bootsector": 0x10400008,
+	"startup":    0x10400009,
+	"bootapp":    0x1020000a,
+	"inherit":    0x20100000,
+	"device":     0x30000000,
+}
+
+// ParseApplySpec reads a YAML or JSON spec. Object ids have to be quoted in
+// YAML, since {bootmgr} would otherwise be read as a mapping.
+func ParseApplySpec(data []byte) (model.ApplySpec, error) {
+	var spec model.ApplySpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Apply converges bcd to spec. Objects missing from the store are created,
+// objects of another type than the spec gives are retyped, elements whose
+// value differs are set and nothing else is written, so
+// applying the same spec twice reports no change the second time.
+func Apply(bcd Bcdedit, spec model.ApplySpec, options ApplyOptions) (*model.ApplyResponse, error) {
+	response := &model.ApplyResponse{
+		Actions: []*model.ApplyAction{},
+	}
+
+	existing, err := bcd.Enumerate("all")
+	if err != nil {
+		return nil, err
+	}
+	// desired values are encoded on a scratch copy and compared by raw bytes
+	scratch, err := CreateMemoryStore()
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	objects := make(map[string]map[string]interface{})
+	for specId, values := range spec {
+		id, err := bcd.ResolveObjectId(specId)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := objects[id]; ok {
+			return nil, fmt.Errorf("object %s is listed more than once", id)
+		}
+		objects[id] = values
+	}
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		values := objects[id]
+		var description model.BcdDescription
+		var hasType bool
+		if value, ok := values[ApplyTypeKey]; ok {
+			description, err = parseObjectType(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "object %s", id)
+			}
+			hasType = true
+		}
+
+		var object BcdObject
+		for existingId, existingObject := range existing {
+			if strings.EqualFold(existingId, id) {
+				object = existingObject
+			}
+		}
+		if object == nil {
+			if !hasType {
+				return nil, fmt.Errorf("object %s does not exist and has no %s", id, ApplyTypeKey)
+			}
+			object, err = bcd.UpsertObject(id, description)
+			if err != nil {
+				return nil, err
+			}
+			response.Actions = append(response.Actions, &model.ApplyAction{
+				Kind:     model.ActionCreateObject,
+				ObjectId: object.GetId(),
+			})
+		} else if hasType && object.GetDescription() != description {
+			if _, err = bcd.UpsertObject(id, description); err != nil {
+				return nil, err
+			}
+			// UpsertObject keeps the stored elements but does not load them
+			object, err = bcd.GetObject(id)
+			if err != nil {
+				return nil, err
+			}
+			response.Actions = append(response.Actions, &model.ApplyAction{
+				Kind:     model.ActionSetType,
+				ObjectId: object.GetId(),
+			})
+		}
+
+		actions, err := applyElements(bcd, scratch, object, values, options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "object %s", object.GetId())
+		}
+		response.Actions = append(response.Actions, actions...)
+	}
+
+	response.Changed = len(response.Actions) > 0
+	return response, nil
+}
+
+func applyElements(bcd Bcdedit, scratch Bcdedit, object BcdObject, values map[string]interface{}, options ApplyOptions) ([]*model.ApplyAction, error) {
+	var actions []*model.ApplyAction
+	desired, err := scratch.UpsertObject(object.GetId(), object.GetDescription())
+	if err != nil {
+		return nil, err
+	}
+	current := object.GetElements()
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if name != ApplyTypeKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	listed := make(map[model.ElementType]string)
+	for _, name := range names {
+		key, err := ResolveOption(object, name)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := listed[key]; ok {
+			return nil, fmt.Errorf("%s and %s both set element %s", other, name, key)
+		}
+		listed[key] = name
+
+		action := &model.ApplyAction{
+			ObjectId: object.GetId(),
+			Element:  key.String(),
+			Name:     name,
+		}
+		element, exists := current[key]
+		if values[name] == nil {
+			if exists {
+				if err = object.DeleteElement(key); err != nil {
+					return nil, err
+				}
+				action.Kind = model.ActionDeleteElement
+				actions = append(actions, action)
+			}
+			continue
+		}
+
+		args, err := specArgs(key, values[name])
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		value, err := SetOptionValue(bcd, desired, key, args)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		if exists && element.GetType() == value.GetType() && bytes.Equal(element.GetRaw(), value.GetRaw()) {
+			continue
+		}
+		if _, err = object.SetElement(key, value.GetType(), value.GetRaw()); err != nil {
+			return nil, err
+		}
+		action.Kind = model.ActionSetElement
+		actions = append(actions, action)
+	}
+
+	if options.Prune {
+		var pruned []model.ElementType
+		for key := range current {
+			if _, ok := listed[key]; !ok {
+				pruned = append(pruned, key)
+			}
+		}
+		slices.Sort(pruned)
+		for _, key := range pruned {
+			if err = object.DeleteElement(key); err != nil {
+				return nil, err
+			}
+			action := &model.ApplyAction{
+				Kind:     model.ActionDeleteElement,
+				ObjectId: object.GetId(),
+				Element:  key.String(),
+			}
+			if option := model.FindOptionByType(key, object.GetDescription().ApplicationType()); option != nil {
+				action.Name = option.Name
+			}
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// specArgs turns a spec value into SetOptionValue arguments. Lists give one
+// argument per item; a string for a list element is split on whitespace.
+func specArgs(key model.ElementType, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		var args []string
+		for _, item := range v {
+			arg, err := specScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		return args, nil
+	case string:
+		switch key.Format() {
+		case model.ElementFormatObjectList, model.ElementFormatIntegerList:
+			return strings.Fields(v), nil
+		}
+	}
+	arg, err := specScalar(value)
+	if err != nil {
+		return nil, err
+	}
+	return []string{arg}, nil
+}
+
+func specScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "on", nil
+		}
+		return "off", nil
+	case int:
+		if v < 0 {
+			return "", fmt.Errorf("negative value: %d", v)
+		}
+		return strconv.Itoa(v), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return "", fmt.Errorf("not an unsigned integer: %v", v)
+		}
+		return strconv.FormatUint(uint64(v), 10), nil
+	}
+	return "", fmt.Errorf("unsupported value: %v", value)
+}
+
+func parseObjectType(value interface{}) (model.BcdDescription, error) {
+	if name, ok := value.(string); ok {
+		if description, ok := objectTypes[strings.ToLower(name)]; ok {
+			return description, nil
+		}
+	}
+	s, err := specScalar(value)
+	if err != nil {
+		return 0, err
+	}
+	n, err := parseInteger(s)
+	if err != nil || n > 0xffffffff {
+		return 0, fmt.Errorf("invalid object type: %v", value)
+	}
+	return model.BcdDescription(n), nil
+}
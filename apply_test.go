@@ -0,0 +1,113 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"testing"
+)
+
+const testApplySpec = `
+"{bootmgr}":
+  timeout: 5
+  displayorder: ["{66666666-7777-4888-9999-aaaaaaaaaaaa}", "{default}"]
+"{66666666-7777-4888-9999-aaaaaaaaaaaa}":
+  type: osloader
+  description: Windows 11
+  device: boot
+  nx: OptIn
+  testsigning: true
+"{11111111-2222-4333-8444-555555555555}":
+  description: null
+`
+
+func actionKinds(response *model.ApplyResponse) []string {
+	var kinds []string
+	for _, action := range response.Actions {
+		kinds = append(kinds, string(action.Kind)+" "+action.ObjectId+" "+action.Element)
+	}
+	return kinds
+}
+
+func TestApply(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	spec, err := ParseApplySpec([]byte(testApplySpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := Apply(bcd, spec, ApplyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Changed {
+		t.Error("first apply reported no change")
+	}
+	// objects in id order, elements in name order
+	want := []string{
+		"delete-element " + testLoaderId + " 12000004",
+		"create-object " + testOtherId + " ",
+		"set-element " + testOtherId + " 12000004",
+		"set-element " + testOtherId + " 11000001",
+		"set-element " + testOtherId + " 25000020",
+		"set-element " + testOtherId + " 16000049",
+		"set-element " + BootMgrObjectId + " 24000001",
+		"set-element " + BootMgrObjectId + " 25000004",
+	}
+	if kinds := actionKinds(response); !slices.Equal(kinds, want) {
+		t.Errorf("got actions %q, want %q", kinds, want)
+	}
+	if got := description(t, bcd, testOtherId); got != "Windows 11" {
+		t.Errorf("description %q", got)
+	}
+	if _, ok := mustGetObject(t, bcd, testLoaderId).GetElements()[model.ElementDescription]; ok {
+		t.Error("null did not delete the element")
+	}
+	if got := displayOrder(t, bcd); len(got) != 2 || got[0] != testOtherId || got[1] != testLoaderId {
+		t.Errorf("display order %v", got)
+	}
+
+	response, err = Apply(bcd, spec, ApplyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Changed || len(response.Actions) != 0 {
+		t.Errorf("second apply: changed=%t, actions %q", response.Changed, actionKinds(response))
+	}
+}
+
+func TestApplyPrune(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	spec := model.ApplySpec{"{bootmgr}": {"timeout": 30}}
+	response, err := Apply(bcd, spec, ApplyOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements := mustGetObject(t, bcd, "{bootmgr}").GetElements()
+	if len(elements) != 1 || len(response.Actions) != 2 {
+		t.Errorf("%d elements left, actions %q", len(elements), actionKinds(response))
+	}
+	if response, err = Apply(bcd, spec, ApplyOptions{Prune: true}); err != nil || response.Changed {
+		t.Errorf("second apply: %+v, %v", response, err)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	for _, spec := range []model.ApplySpec{
+		{testOtherId: {"description": "no type"}},
+		{testLoaderId: {"nosuchoption": 1}},
+		{testLoaderId: {"nx": "Sometimes"}},
+		{testLoaderId: {"description": "a", "12000004": "b"}},
+		{testLoaderId: {"type": "nosuchtype"}},
+		{"{default}": {"description": "a"}, testLoaderId: {"description": "b"}},
+	} {
+		if _, err := Apply(bcd, spec, ApplyOptions{}); err == nil {
+			t.Errorf("%v: expected an error", spec)
+		}
+	}
+}
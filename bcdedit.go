@@ -1,44 +1,101 @@
 package go_bcdedit
 
 import (
-	"github.com/gabriel-samfira/go-hivex"
 	"github.com/jc-lab/go-bcdedit/internal/bcdtemplate"
 	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
+	"github.com/jc-lab/go-bcdedit/pkg/regf"
 	"github.com/pkg/errors"
 	"io"
-	"os"
 )
 
+// ErrObjectNotFound is returned, possibly wrapped, for an id that names no
+// stored object.
+var ErrObjectNotFound = errors.New("object does not exist")
+
 type Bcdedit interface {
 	io.Closer
 	Enumerate(objectId string) (map[string]BcdObject, error)
 	UpsertObject(objectId string, description model.BcdDescription) (BcdObject, error)
 	GetObject(objectId string) (BcdObject, error)
+	DeleteObject(objectId string) error
+	CopyObject(srcId string, newId string, description string) (BcdObject, error)
+	ResolveObjectId(objectId string) (string, error)
+	Validate() ([]*model.ValidationIssue, error)
+
+	Begin() error
+	Commit() error
+	Rollback() error
+	Savepoint(name string) error
+	RollbackTo(name string) error
 }
 
 func CreateStore(store string) (Bcdedit, error) {
-	err := os.WriteFile(store, bcdtemplate.EMPTY, 0644)
+	return CreateStoreWithLock(store, LockOptions{})
+}
+
+// CreateStoreWithLock creates a store like CreateStore while holding the
+// store lock described by options until the returned store is closed.
+func CreateStoreWithLock(store string, options LockOptions) (Bcdedit, error) {
+	lock, err := lockStore(store, true, options)
 	if err != nil {
+		return nil, errors.Wrap(err, "locking store")
+	}
+
+	// like Commit, a crash leaves the old file or the new store, never a torn hive
+	err = regf.WriteFileAtomic(store, bcdtemplate.EMPTY)
+	if err != nil {
+		lock.Unlock()
 		return nil, errors.Wrap(err, "failed to create file")
 	}
 
-	h, err := hivex.NewHivex(store, hivex.WRITE)
+	h, err := regf.Open(store, regf.WRITE)
 	if err != nil {
+		lock.Unlock()
 		return nil, errors.Wrap(err, "opening hive file")
 	}
 
-	return NewWithHive(h, true)
+	return &HiveBcdedit{
+		Hive:     h,
+		Writable: true,
+		lock:     lock,
+	}, nil
 }
 
 func OpenStore(store string, writable bool) (Bcdedit, error) {
-	var flags = hivex.READ
+	return OpenStoreWithLock(store, writable, LockOptions{})
+}
+
+// OpenStoreWithLock opens a store like OpenStore while holding the store
+// lock described by options until the returned store is closed.
+func OpenStoreWithLock(store string, writable bool, options LockOptions) (Bcdedit, error) {
+	lock, err := lockStore(store, writable, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "locking store")
+	}
+
+	var flags = regf.READ
 	if writable {
-		flags |= hivex.WRITE
+		flags |= regf.WRITE
 	}
-	h, err := hivex.NewHivex(store, flags)
+	h, err := regf.Open(store, flags)
 	if err != nil {
+		lock.Unlock()
 		return nil, errors.Wrap(err, "opening hive file")
 	}
 
-	return NewWithHive(h, writable)
+	return &HiveBcdedit{
+		Hive:     h,
+		Writable: writable,
+		lock:     lock,
+	}, nil
+}
+
+// CreateMemoryStore creates a new and empty store that lives only in memory.
+func CreateMemoryStore() (Bcdedit, error) {
+	h := hive.NewMemory("NewStoreRoot")
+	if err := bcdtemplate.Init(h); err != nil {
+		return nil, errors.Wrap(err, "initializing memory store")
+	}
+	return NewWithHive(h, true)
 }
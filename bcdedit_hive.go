@@ -3,25 +3,27 @@ package go_bcdedit
 import (
 	"encoding/base64"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"github.com/gabriel-samfira/go-hivex"
 	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
 	"github.com/jc-lab/go-bcdedit/pkg/hiveutil"
+	"strings"
 )
 
 const (
-	RegNone                     ValueType = hivex.RegNone
-	RegSz                       ValueType = hivex.RegSz
-	RegExpandSz                 ValueType = hivex.RegExpandSz
-	RegBinary                   ValueType = hivex.RegBinary
-	RegDword                    ValueType = hivex.RegDword
-	RegDwordBigEndian           ValueType = hivex.RegDwordBigEndian
-	RegLink                     ValueType = hivex.RegLink
-	RegMultiSz                  ValueType = hivex.RegMultiSz
-	RegResourceList             ValueType = hivex.RegResourceList
-	RegFullResourceDescriptor   ValueType = hivex.RegFullResourceDescriptor
-	RegResourceRequirementsList ValueType = hivex.RegResourceRequirementsList
-	RegQword                    ValueType = hivex.RegQword
+	RegNone                     ValueType = hive.RegNone
+	RegSz                       ValueType = hive.RegSz
+	RegExpandSz                 ValueType = hive.RegExpandSz
+	RegBinary                   ValueType = hive.RegBinary
+	RegDword                    ValueType = hive.RegDword
+	RegDwordBigEndian           ValueType = hive.RegDwordBigEndian
+	RegLink                     ValueType = hive.RegLink
+	RegMultiSz                  ValueType = hive.RegMultiSz
+	RegResourceList             ValueType = hive.RegResourceList
+	RegFullResourceDescriptor   ValueType = hive.RegFullResourceDescriptor
+	RegResourceRequirementsList ValueType = hive.RegResourceRequirementsList
+	RegQword                    ValueType = hive.RegQword
 )
 
 func (t ValueType) ToJson() model.ValueType {
@@ -84,33 +86,67 @@ func ValueTypeFromJson(t model.ValueType) ValueType {
 	return RegNone
 }
 
+// HiveBcdedit is a Bcdedit backed by a registry hive. It is not safe for
+// concurrent use; wrap it with NewSyncBcdedit to share it between goroutines.
 type HiveBcdedit struct {
-	Hive     *hivex.Hivex
+	Hive     hive.Hive
 	Writable bool
+	Aliases  *AliasResolver // resolves identifiers given to the methods, may be nil
+
+	savepoints []savepoint // nil when no transaction is active
+
+	lock *storeLock // released by Close
+}
+
+type savepoint struct {
+	name     string
+	snapshot hive.Snapshot
 }
 
-func NewWithHive(hive *hivex.Hivex, writable bool) (Bcdedit, error) {
+func NewWithHive(hive hive.Hive, writable bool) (Bcdedit, error) {
 	return &HiveBcdedit{
 		Hive:     hive,
 		Writable: writable,
 	}, nil
 }
 
+// Close commits a writable store unless a transaction is open, in which
+// case nothing is written and the transaction is rolled back.
 func (b *HiveBcdedit) Close() error {
 	var err error
-	if b.Writable {
+	if b.Writable && b.savepoints == nil {
 		_, err = b.Hive.Commit()
 	}
+	b.savepoints = nil
 	closeErr := b.Hive.Close()
+	unlockErr := b.lock.Unlock()
+	b.lock = nil
 	if err != nil {
 		return err
 	}
-	return closeErr
+	if closeErr != nil {
+		return closeErr
+	}
+	return unlockErr
+}
+
+// ResolveObjectId resolves aliases such as {bootmgr} or {default} and
+// canonicalizes GUIDs.
+func (b *HiveBcdedit) ResolveObjectId(objectId string) (string, error) {
+	return b.Aliases.Resolve(b, objectId)
 }
 
 func (b *HiveBcdedit) Enumerate(targetObjectId string) (map[string]BcdObject, error) {
 	objectMap := map[string]BcdObject{}
 
+	if len(targetObjectId) > 0 && targetObjectId != "all" {
+		resolved, err := b.ResolveObjectId(targetObjectId)
+		if err != nil {
+			return nil, err
+		}
+		targetObjectId = resolved
+	}
+
 	root, err := hiveutil.GetObjectsNode(b.Hive)
 	if err != nil {
 		return nil, err
@@ -121,7 +157,7 @@ func (b *HiveBcdedit) Enumerate(targetObjectId string) (map[string]BcdObject, er
 		}
 		var object BcdObject
 		if len(targetObjectId) > 0 && targetObjectId != "all" {
-			if objectId != targetObjectId {
+			if !strings.EqualFold(objectId, targetObjectId) {
 				return nil
 			}
 		}
@@ -140,6 +176,10 @@ func (b *HiveBcdedit) Enumerate(targetObjectId string) (map[string]BcdObject, er
 }
 
 func (b *HiveBcdedit) GetObject(objectId string) (BcdObject, error) {
+	objectId, err := b.ResolveObjectId(objectId)
+	if err != nil {
+		return nil, err
+	}
 	root, err := hiveutil.GetObjectsNode(b.Hive)
 	if err != nil {
 		return nil, err
@@ -149,9 +189,14 @@ func (b *HiveBcdedit) GetObject(objectId string) (BcdObject, error) {
 		return nil, err
 	}
 	if objectNode == 0 {
-		return nil, fmt.Errorf("not exists %s", objectId)
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, objectId)
+	}
+	// report the id as stored, whatever case it was looked up with
+	storedId, err := b.Hive.NodeName(objectNode)
+	if err != nil {
+		return nil, err
 	}
-	return b.getObject(objectId, objectNode)
+	return b.getObject(storedId, objectNode)
 }
 
 func (b *HiveBcdedit) getObject(objectId string, objectNode int64) (BcdObject, error) {
@@ -173,7 +218,7 @@ func (b *HiveBcdedit) getObject(objectId string, objectNode int64) (BcdObject, e
 	if err != nil {
 		return nil, fmt.Errorf("%s\\Description\\Type type read failed: %+v", objectId, err)
 	}
-	if valType != hivex.RegDword {
+	if valType != hive.RegDword {
 		return nil, fmt.Errorf("%s\\Description\\Type type is %d not dword", objectId, valType)
 	}
 	elementsNode, err := hiveutil.FindChild(b.Hive, objectNode, "Elements")
@@ -186,7 +231,7 @@ func (b *HiveBcdedit) getObject(objectId string, objectNode int64) (BcdObject, e
 		Id:           objectId,
 		ElementsNode: elementsNode,
 		Description:  model.BcdDescription(binary.LittleEndian.Uint32(valueBytes)),
-		Elements:     make(map[string]*HiveBcdElement),
+		Elements:     make(map[model.ElementType]*HiveBcdElement),
 	}
 	err = object.readElements(b)
 	if err != nil {
@@ -195,7 +240,13 @@ func (b *HiveBcdedit) getObject(objectId string, objectNode int64) (BcdObject, e
 	return object, nil
 }
 
+// UpsertObject creates the object or updates the type of an existing one.
+// The id must be a GUID or an alias and is stored in canonical form.
 func (b *HiveBcdedit) UpsertObject(objectId string, description model.BcdDescription) (BcdObject, error) {
+	objectId, err := b.ResolveObjectId(objectId)
+	if err != nil {
+		return nil, err
+	}
 	root, err := hiveutil.GetObjectsNode(b.Hive)
 	if err != nil {
 		return nil, err
@@ -204,12 +255,16 @@ func (b *HiveBcdedit) UpsertObject(objectId string, description model.BcdDescrip
 	if err != nil {
 		return nil, err
 	}
+	objectId, err = b.Hive.NodeName(objectNode)
+	if err != nil {
+		return nil, err
+	}
 	descriptionNode, err := hiveutil.UpsertNode(b.Hive, objectNode, "Description")
 	if err != nil {
 		return nil, err
 	}
-	_, err = b.Hive.NodeSetValue(descriptionNode, hivex.HiveValue{
-		Type:  hivex.RegDword,
+	_, err = b.Hive.NodeSetValue(descriptionNode, hive.Value{
+		Type:  hive.RegDword,
 		Key:   "Type",
 		Value: binary.LittleEndian.AppendUint32(nil, uint32(description)),
 	})
@@ -227,12 +282,74 @@ func (b *HiveBcdedit) UpsertObject(objectId string, description model.BcdDescrip
 		ElementsNode: elementsNode,
 		Id:           objectId,
 		Description:  description,
-		Elements:     make(map[string]*HiveBcdElement),
+		Elements:     make(map[model.ElementType]*HiveBcdElement),
 	}
 	return object, nil
 }
 
-func (b *HiveBcdedit) getElement(parent *HiveBcdObject, node int64, key string, value int64) (*HiveBcdElement, error) {
+func (b *HiveBcdedit) DeleteObject(objectId string) error {
+	objectId, err := b.ResolveObjectId(objectId)
+	if err != nil {
+		return err
+	}
+	root, err := hiveutil.GetObjectsNode(b.Hive)
+	if err != nil {
+		return err
+	}
+	objectNode, err := hiveutil.FindChild(b.Hive, root, objectId)
+	if err != nil {
+		return err
+	}
+	if objectNode == 0 {
+		return fmt.Errorf("%w: %s", ErrObjectNotFound, objectId)
+	}
+	_, err = b.Hive.NodeDeleteChild(objectNode)
+	return err
+}
+
+// CopyObject duplicates an object's type and elements into newId, which is
+// generated when empty. A non-empty description replaces the copied one.
+func (b *HiveBcdedit) CopyObject(srcId string, newId string, description string) (BcdObject, error) {
+	src, err := b.GetObject(srcId)
+	if err != nil {
+		return nil, err
+	}
+	if newId == "" {
+		id, err := model.NewObjectId()
+		if err != nil {
+			return nil, err
+		}
+		newId = id.String()
+	}
+	newId, err = b.ResolveObjectId(newId)
+	if err != nil {
+		return nil, err
+	}
+	_, err = b.GetObject(newId)
+	if err == nil {
+		return nil, fmt.Errorf("already exists %s", newId)
+	}
+	if !errors.Is(err, ErrObjectNotFound) {
+		return nil, err
+	}
+
+	object, err := b.UpsertObject(newId, src.GetDescription())
+	if err != nil {
+		return nil, err
+	}
+	if err = copyElements(object, src); err != nil {
+		return nil, err
+	}
+	if description != "" {
+		_, err = object.SetString(model.ElementDescription, description)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+func (b *HiveBcdedit) getElement(parent *HiveBcdObject, node int64, key model.ElementType, value int64) (*HiveBcdElement, error) {
 	element := &HiveBcdElement{
 		Parent: parent,
 		Node:   node,
@@ -250,7 +367,7 @@ func (b *HiveBcdedit) getElement(parent *HiveBcdObject, node int64, key string,
 type HiveBcdElement struct {
 	Parent *HiveBcdObject
 	Node   int64
-	Key    string
+	Key    model.ElementType
 	Type   ValueType
 	Raw    []byte
 }
@@ -273,7 +390,7 @@ type HiveBcdObject struct {
 
 	Description model.BcdDescription
 
-	Elements map[string]*HiveBcdElement // e.g. key="11000001"
+	Elements map[model.ElementType]*HiveBcdElement // e.g. key=0x11000001
 }
 
 func (o *HiveBcdObject) readElements(bcd *HiveBcdedit) error {
@@ -281,20 +398,24 @@ func (o *HiveBcdObject) readElements(bcd *HiveBcdedit) error {
 		return nil
 	}
 
-	elements := make(map[string]*HiveBcdElement)
+	elements := make(map[model.ElementType]*HiveBcdElement)
 	err := hiveutil.ReadNode(bcd.Hive, o.ElementsNode, func(node int64, name string, err error) error {
 		if err != nil {
 			return err
 		}
+		key, err := model.ParseElementType(name)
+		if err != nil {
+			return fmt.Errorf("%s\\Elements: %v", o.Id, err)
+		}
 		value, err := hiveutil.FindValue(bcd.Hive, node, "Element")
 		if err != nil {
 			return err
 		}
-		element, err := bcd.getElement(o, node, name, value)
+		element, err := bcd.getElement(o, node, key, value)
 		if err != nil {
 			return err
 		}
-		elements[name] = element
+		elements[key] = element
 		return nil
 	})
 	if err != nil {
@@ -312,37 +433,45 @@ func (o *HiveBcdObject) GetDescription() model.BcdDescription {
 	return o.Description
 }
 
-func (o *HiveBcdObject) GetElements() map[string]BcdElement {
-	fixedMap := make(map[string]BcdElement)
+func (o *HiveBcdObject) GetElements() map[model.ElementType]BcdElement {
+	fixedMap := make(map[model.ElementType]BcdElement)
 	for key, element := range o.Elements {
 		fixedMap[key] = element
 	}
 	return fixedMap
 }
 
-func (o *HiveBcdObject) ToJson() *model.BcdObject {
-	elements := make(map[string]*model.BcdElement)
-	for key, element := range o.Elements {
-		jsonElement := &model.BcdElement{
-			Type: element.GetType().ToJson(),
-			Raw:  base64.StdEncoding.EncodeToString(element.GetRaw()),
-		}
+func (e *HiveBcdElement) ToJson() *model.BcdElement {
+	jsonElement := &model.BcdElement{
+		Type: e.GetType().ToJson(),
+		Raw:  base64.StdEncoding.EncodeToString(e.GetRaw()),
+	}
+
+	switch e.GetType() {
+	case RegSz:
+		jsonElement.ValueSz, _ = e.GetString()
 
-		switch element.GetType() {
-		case RegSz:
-			jsonElement.ValueSz, _ = element.GetString()
+	case RegMultiSz:
+		jsonElement.ValueMultiSz, _ = e.GetMultiStrings()
 
-		case RegMultiSz:
-			jsonElement.ValueMultiSz, _ = element.GetMultiStrings()
+	case RegDword:
+		valueDword, err := e.GetDword()
+		if err == nil {
+			jsonElement.ValueDword = &valueDword
+		}
 
-		case RegDword:
-			valueDword, err := element.GetDword()
-			if err == nil {
-				jsonElement.ValueDword = &valueDword
-			}
+	case RegBinary:
+		if e.Key.Format() == model.ElementFormatDevice {
+			jsonElement.ValueDevice, _ = e.GetDevice()
 		}
+	}
+	return jsonElement
+}
 
-		elements[key] = jsonElement
+func (o *HiveBcdObject) ToJson() *model.BcdObject {
+	elements := make(map[string]*model.BcdElement)
+	for key, element := range o.Elements {
+		elements[key.String()] = element.ToJson()
 	}
 	return &model.BcdObject{
 		Description: o.Description,
@@ -0,0 +1,126 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testLoaderId = "{11111111-2222-4333-8444-555555555555}"
+	testOtherId  = "{66666666-7777-4888-9999-aaaaaaaaaaaa}"
+)
+
+var (
+	bootmgrType  = model.BcdDescriptionFrom(model.ObjectApplication, model.WindowsBootApplication, model.ApplicationBootmgr)
+	osloaderType = model.BcdDescriptionFrom(model.ObjectApplication, model.WindowsBootApplication, model.ApplicationOsloader)
+)
+
+// newTestStore returns a memory store holding {bootmgr} with a single
+// Windows boot loader as its default and display order.
+func newTestStore(t *testing.T) Bcdedit {
+	t.Helper()
+	bcd, err := CreateMemoryStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := bcd.UpsertObject("{bootmgr}", bootmgrType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader, err := bcd.UpsertObject(testLoaderId, osloaderType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSet(t)(loader.SetString(model.ElementDescription, "Windows"))
+	mustSet(t)(manager.SetObjectList(model.ElementDisplayOrder, []string{testLoaderId}))
+	mustSet(t)(manager.SetObject(model.ElementDefaultObject, testLoaderId))
+	mustSet(t)(manager.SetInteger(model.ElementTimeout, 30))
+	return bcd
+}
+
+func mustSet(t *testing.T) func(BcdElement, error) {
+	return func(_ BcdElement, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func mustGetObject(t *testing.T, bcd Bcdedit, id string) BcdObject {
+	t.Helper()
+	object, err := bcd.GetObject(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return object
+}
+
+func TestMemoryStore(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	object := mustGetObject(t, bcd, "{11111111-2222-4333-8444-555555555555}")
+	if object.GetId() != testLoaderId || object.GetDescription() != osloaderType {
+		t.Errorf("got %s %08x", object.GetId(), uint32(object.GetDescription()))
+	}
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("%d objects, want 2", len(objects))
+	}
+	if _, err = bcd.GetObject(testOtherId); err == nil {
+		t.Error("missing object: expected an error")
+	}
+}
+
+func TestStoreFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "BCD")
+	bcd, err := CreateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bcd.UpsertObject(testLoaderId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bcd, err = OpenStore(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bcd.Close()
+	if object := mustGetObject(t, bcd, testLoaderId); object.GetDescription() != osloaderType {
+		t.Errorf("reopened type %08x", uint32(object.GetDescription()))
+	}
+}
+
+func TestObjectIdCase(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	upper := strings.ToUpper(testOtherId)
+	object, err := bcd.UpsertObject(upper, osloaderType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.GetId() != testOtherId {
+		t.Errorf("created as %s, want the canonical %s", object.GetId(), testOtherId)
+	}
+	if object = mustGetObject(t, bcd, strings.ToUpper(testLoaderId)); object.GetId() != testLoaderId {
+		t.Errorf("looked up as %s, want the stored %s", object.GetId(), testLoaderId)
+	}
+	objects, err := bcd.Enumerate(upper)
+	if err != nil || len(objects) != 1 {
+		t.Errorf("enumerate by upper case id: %d objects, %v", len(objects), err)
+	}
+	if _, err = bcd.UpsertObject("{not-a-guid}", osloaderType); err == nil {
+		t.Error("invalid id: expected an error")
+	}
+}
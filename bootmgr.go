@@ -0,0 +1,97 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+)
+
+// ListOperation selects how UpdateObjectList changes an object list.
+type ListOperation int
+
+const (
+	ListReplace ListOperation = iota
+	ListAddFirst
+	ListAddLast
+	ListRemove
+)
+
+// ResolveExistingObjectId resolves an identifier and checks that the
+// object exists in the store.
+func ResolveExistingObjectId(bcd Bcdedit, objectId string) (string, error) {
+	resolved, err := bcd.ResolveObjectId(objectId)
+	if err != nil {
+		return "", err
+	}
+	_, err = bcd.GetObject(resolved)
+	if errors.Is(err, ErrObjectNotFound) {
+		return "", fmt.Errorf("the specified entry does not exist: %s", objectId)
+	}
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// UpdateObjectList edits an object list element such as the DisplayOrder of
+// {bootmgr} or {fwbootmgr} the way bcdedit's /addfirst, /addlast and /remove
+// do. Added ids must exist and are moved if already listed; a list left
+// empty by ListRemove is deleted.
+func UpdateObjectList(bcd Bcdedit, object BcdObject, key model.ElementType, ids []string, op ListOperation) error {
+	if key.Format() != model.ElementFormatObjectList {
+		return fmt.Errorf("element %s is not a %s element", key, model.ElementFormatObjectList)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("element %s needs at least one identifier", key)
+	}
+
+	var resolved []string
+	for _, id := range ids {
+		var err error
+		if op == ListRemove {
+			id, err = bcd.ResolveObjectId(id)
+		} else {
+			id, err = ResolveExistingObjectId(bcd, id)
+		}
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, id)
+	}
+
+	var current []string
+	if element, ok := object.GetElements()[key]; ok {
+		var err error
+		current, err = element.GetObjectList()
+		if err != nil {
+			return err
+		}
+	}
+	others := slices.DeleteFunc(slices.Clone(current), func(id string) bool {
+		return slices.ContainsFunc(resolved, func(r string) bool {
+			return strings.EqualFold(id, r)
+		})
+	})
+
+	var list []string
+	switch op {
+	case ListReplace:
+		list = resolved
+	case ListAddFirst:
+		list = append(resolved, others...)
+	case ListAddLast:
+		list = append(others, resolved...)
+	case ListRemove:
+		if len(others) == len(current) {
+			return nil
+		}
+		if len(others) == 0 {
+			return object.DeleteElement(key)
+		}
+		list = others
+	}
+	_, err := object.SetObjectList(key, list)
+	return err
+}
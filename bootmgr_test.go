@@ -0,0 +1,73 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"testing"
+)
+
+func displayOrder(t *testing.T, bcd Bcdedit) []string {
+	t.Helper()
+	element, ok := mustGetObject(t, bcd, "{bootmgr}").GetElements()[model.ElementDisplayOrder]
+	if !ok {
+		return nil
+	}
+	ids, err := element.GetObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestUpdateObjectList(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	if _, err := bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		op   ListOperation
+		ids  []string
+		want []string
+	}{
+		{ListAddLast, []string{testOtherId}, []string{testLoaderId, testOtherId}},
+		{ListAddFirst, []string{testOtherId}, []string{testOtherId, testLoaderId}},
+		{ListAddLast, []string{"{default}"}, []string{testOtherId, testLoaderId}},
+		{ListRemove, []string{"{11111111-2222-4333-8444-555555555555}"}, []string{testOtherId}},
+		{ListRemove, []string{"{bootmgr}"}, []string{testOtherId}},
+		{ListReplace, []string{testLoaderId, testOtherId}, []string{testLoaderId, testOtherId}},
+		{ListRemove, []string{testLoaderId, testOtherId}, nil},
+	}
+	for i, test := range tests {
+		if err := UpdateObjectList(bcd, mustGetObject(t, bcd, "{bootmgr}"), model.ElementDisplayOrder, test.ids, test.op); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got := displayOrder(t, bcd); !slices.Equal(got, test.want) {
+			t.Errorf("%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestUpdateObjectListErrors(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	manager := mustGetObject(t, bcd, "{bootmgr}")
+	if err := UpdateObjectList(bcd, manager, model.ElementDisplayOrder, []string{testOtherId}, ListAddLast); err == nil {
+		t.Error("adding a missing object: expected an error")
+	}
+	if err := UpdateObjectList(bcd, manager, model.ElementDisplayOrder, nil, ListAddLast); err == nil {
+		t.Error("no ids: expected an error")
+	}
+	if err := UpdateObjectList(bcd, manager, model.ElementDefaultObject, []string{testLoaderId}, ListAddLast); err == nil {
+		t.Error("object element: expected an error")
+	}
+	// removing an id that is not listed leaves the list alone
+	if err := UpdateObjectList(bcd, manager, model.ElementDisplayOrder, []string{testOtherId}, ListRemove); err != nil {
+		t.Error(err)
+	}
+	if got := displayOrder(t, bcd); !slices.Equal(got, []string{testLoaderId}) {
+		t.Errorf("got %v", got)
+	}
+}
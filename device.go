@@ -0,0 +1,361 @@
+package go_bcdedit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"strconv"
+	"strings"
+)
+
+// Device element layout:
+//
+//	0x00 GUID     additional options object (e.g. ramdisk options), zero if none
+//	0x10 DESCRIPTOR
+//
+// A descriptor starts with a 16-byte header {reserved, type, flags, size},
+// where size covers the whole descriptor including the header. Partition
+// devices carry a 16-byte partition id (GPT partition GUID or MBR byte offset)
+// followed by a block I/O payload describing the disk. Block I/O payloads start
+// with their block I/O type; file-backed ones (ramdisk, file, vhd) hold a
+// nested parent descriptor followed by a NUL-terminated UTF-16 path.
+const (
+	deviceOptionsSize  = 16
+	deviceHeaderSize   = 16
+	partitionIdSize    = 16
+	hardDiskDataSize   = 40
+	bootDataSize       = 8
+	locateDataHeadSize = 12
+)
+
+// ParseDevice decodes the raw value of a Device-format element.
+func ParseDevice(raw []byte) (*model.Device, error) {
+	if len(raw) < deviceOptionsSize+deviceHeaderSize {
+		return nil, fmt.Errorf("device element too short: %d bytes", len(raw))
+	}
+	device := &model.Device{}
+	if options := model.GuidFromBytes(raw[:deviceOptionsSize]); !options.IsZero() {
+		device.OptionsId = options.String()
+	}
+	descriptor, _, err := parseDeviceDescriptor(raw[deviceOptionsSize:])
+	if err != nil {
+		return nil, err
+	}
+	device.DeviceDescriptor = *descriptor
+	return device, nil
+}
+
+// parseDeviceDescriptor returns the descriptor and the number of bytes it occupies.
+func parseDeviceDescriptor(b []byte) (*model.DeviceDescriptor, int, error) {
+	if len(b) < deviceHeaderSize {
+		return nil, 0, fmt.Errorf("device descriptor too short: %d bytes", len(b))
+	}
+	descriptor := &model.DeviceDescriptor{
+		Type:  model.DeviceType(binary.LittleEndian.Uint32(b[4:])),
+		Flags: binary.LittleEndian.Uint32(b[8:]),
+	}
+	size := int(binary.LittleEndian.Uint32(b[12:]))
+	if size < deviceHeaderSize || size > len(b) {
+		return nil, 0, fmt.Errorf("invalid device descriptor size: %d", size)
+	}
+	data := b[deviceHeaderSize:size]
+
+	switch descriptor.Type {
+	case model.DeviceBoot:
+	case model.DevicePartition, model.DeviceQualifiedPartition:
+		if len(data) < partitionIdSize+4 {
+			return nil, 0, fmt.Errorf("partition device too short: %d bytes", len(data))
+		}
+		disk, err := parseBlockIo(data[partitionIdSize:])
+		if err != nil {
+			return nil, 0, err
+		}
+		partition := &model.PartitionDevice{Disk: *disk}
+		switch {
+		case descriptor.Type == model.DevicePartition:
+			partition.Number = binary.LittleEndian.Uint32(data)
+		case disk.Disk != nil && disk.Disk.PartitionStyle == model.PartitionStyleMbr:
+			partition.MbrOffset = binary.LittleEndian.Uint64(data)
+		default:
+			id := model.GuidFromBytes(data[:partitionIdSize])
+			partition.GptPartitionId = &id
+		}
+		descriptor.Partition = partition
+	case model.DeviceBlockIo:
+		blockIo, err := parseBlockIo(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		descriptor.BlockIo = blockIo
+	case model.DeviceLocate:
+		if len(data) < locateDataHeadSize {
+			return nil, 0, fmt.Errorf("locate device too short: %d bytes", len(data))
+		}
+		locate := &model.LocateDevice{
+			Type:         binary.LittleEndian.Uint32(data[0:]),
+			ElementType:  binary.LittleEndian.Uint32(data[4:]),
+			ParentOffset: binary.LittleEndian.Uint32(data[8:]),
+		}
+		path := data[locateDataHeadSize:]
+		_, locate.Path, _ = Utf16LEToString(path[:len(path)&^1])
+		descriptor.Locate = locate
+	default:
+		descriptor.Raw = append([]byte(nil), data...)
+	}
+	return descriptor, size, nil
+}
+
+func parseBlockIo(b []byte) (*model.BlockIoDevice, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("block io device too short: %d bytes", len(b))
+	}
+	blockIo := &model.BlockIoDevice{
+		Type: model.BlockIoType(binary.LittleEndian.Uint32(b)),
+	}
+	switch blockIo.Type {
+	case model.BlockIoHardDisk, model.BlockIoRemovableDisk, model.BlockIoCdRom:
+		if len(b) < 24 {
+			return nil, fmt.Errorf("disk device too short: %d bytes", len(b))
+		}
+		disk := &model.HardDisk{
+			PartitionStyle: model.PartitionStyle(binary.LittleEndian.Uint32(b[4:])),
+		}
+		switch disk.PartitionStyle {
+		case model.PartitionStyleGpt:
+			id := model.GuidFromBytes(b[8:24])
+			disk.GptDiskId = &id
+		case model.PartitionStyleMbr:
+			disk.MbrSignature = binary.LittleEndian.Uint32(b[8:])
+		default:
+			disk.DiskNumber = binary.LittleEndian.Uint32(b[8:])
+		}
+		blockIo.Disk = disk
+	case model.BlockIoRamdisk, model.BlockIoFile, model.BlockIoVirtualHardDisk:
+		parent, n, err := parseDeviceDescriptor(b[4:])
+		if err != nil {
+			return nil, err
+		}
+		path := b[4+n:]
+		_, s, err := Utf16LEToString(path[:len(path)&^1])
+		if err != nil {
+			return nil, err
+		}
+		blockIo.File = &model.FilePath{
+			Parent: *parent,
+			Path:   s,
+		}
+	}
+	return blockIo, nil
+}
+
+// MarshalDevice encodes a device into the raw value of a Device-format element.
+func MarshalDevice(device *model.Device) ([]byte, error) {
+	raw := make([]byte, deviceOptionsSize)
+	if device.OptionsId != "" {
+		options, err := model.ParseGuid(device.OptionsId)
+		if err != nil {
+			return nil, err
+		}
+		copy(raw, options[:])
+	}
+	descriptor, err := marshalDeviceDescriptor(&device.DeviceDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, descriptor...), nil
+}
+
+func marshalDeviceDescriptor(descriptor *model.DeviceDescriptor) ([]byte, error) {
+	var data []byte
+	switch descriptor.Type {
+	case model.DeviceBoot:
+		data = make([]byte, bootDataSize)
+	case model.DevicePartition, model.DeviceQualifiedPartition:
+		partition := descriptor.Partition
+		if partition == nil {
+			return nil, fmt.Errorf("%s device without partition", descriptor.Type)
+		}
+		data = make([]byte, partitionIdSize)
+		switch {
+		case descriptor.Type == model.DevicePartition:
+			binary.LittleEndian.PutUint32(data, partition.Number)
+		case partition.GptPartitionId != nil:
+			copy(data, partition.GptPartitionId[:])
+		default:
+			binary.LittleEndian.PutUint64(data, partition.MbrOffset)
+		}
+		disk, err := marshalBlockIo(&partition.Disk)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, disk...)
+	case model.DeviceBlockIo:
+		if descriptor.BlockIo == nil {
+			return nil, fmt.Errorf("%s device without block io", descriptor.Type)
+		}
+		blockIo, err := marshalBlockIo(descriptor.BlockIo)
+		if err != nil {
+			return nil, err
+		}
+		data = blockIo
+	case model.DeviceLocate:
+		if descriptor.Locate == nil {
+			return nil, fmt.Errorf("%s device without locate", descriptor.Type)
+		}
+		data = make([]byte, locateDataHeadSize)
+		binary.LittleEndian.PutUint32(data[0:], descriptor.Locate.Type)
+		binary.LittleEndian.PutUint32(data[4:], descriptor.Locate.ElementType)
+		binary.LittleEndian.PutUint32(data[8:], descriptor.Locate.ParentOffset)
+		path, err := StringToUtf16LE(descriptor.Locate.Path + "\x00")
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, path...)
+	default:
+		data = descriptor.Raw
+	}
+
+	header := make([]byte, deviceHeaderSize)
+	binary.LittleEndian.PutUint32(header[4:], uint32(descriptor.Type))
+	binary.LittleEndian.PutUint32(header[8:], descriptor.Flags)
+	binary.LittleEndian.PutUint32(header[12:], uint32(deviceHeaderSize+len(data)))
+	return append(header, data...), nil
+}
+
+func marshalBlockIo(blockIo *model.BlockIoDevice) ([]byte, error) {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(blockIo.Type))
+	switch blockIo.Type {
+	case model.BlockIoHardDisk, model.BlockIoRemovableDisk, model.BlockIoCdRom:
+		disk := blockIo.Disk
+		if disk == nil {
+			return nil, fmt.Errorf("%s device without disk", blockIo.Type)
+		}
+		data = binary.LittleEndian.AppendUint32(data, uint32(disk.PartitionStyle))
+		signature := make([]byte, hardDiskDataSize-len(data))
+		switch disk.PartitionStyle {
+		case model.PartitionStyleGpt:
+			if disk.GptDiskId == nil {
+				return nil, fmt.Errorf("gpt disk without disk id")
+			}
+			copy(signature, disk.GptDiskId[:])
+		case model.PartitionStyleMbr:
+			binary.LittleEndian.PutUint32(signature, disk.MbrSignature)
+		default:
+			binary.LittleEndian.PutUint32(signature, disk.DiskNumber)
+		}
+		data = append(data, signature...)
+	case model.BlockIoRamdisk, model.BlockIoFile, model.BlockIoVirtualHardDisk:
+		if blockIo.File == nil {
+			return nil, fmt.Errorf("%s device without file", blockIo.Type)
+		}
+		parent, err := marshalDeviceDescriptor(&blockIo.File.Parent)
+		if err != nil {
+			return nil, err
+		}
+		path, err := StringToUtf16LE(blockIo.File.Path + "\x00")
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, parent...)
+		data = append(data, path...)
+	}
+	return data, nil
+}
+
+// ParseDeviceString parses the bcdedit-like device syntax produced by
+// model.Device.String:
+//
+//	boot
+//	partition=gpt:<disk guid>:<partition guid>
+//	partition=mbr:<disk signature(hex)>:<partition offset in bytes>
+//	locate[=<path>]
+//	ramdisk=[<parent>]<path>[,<options object id>]
+//	file=[<parent>]<path>
+//	vhd=[<parent>]<path>
+//
+// where <parent> is boot, locate or a gpt:/mbr: partition spec.
+func ParseDeviceString(s string) (*model.Device, error) {
+	kind, spec, _ := strings.Cut(strings.TrimSpace(s), "=")
+	switch strings.ToLower(kind) {
+	case "boot":
+		return model.NewBootDevice(), nil
+	case "locate":
+		return model.NewLocateDevice(spec), nil
+	case "partition":
+		return parsePartitionSpec(spec)
+	case "ramdisk", "file", "vhd":
+		parent, path, err := parseFilePathSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(kind) {
+		case "file":
+			return model.NewFileDevice(*parent, path), nil
+		case "vhd":
+			return model.NewVhdDevice(*parent, path), nil
+		}
+		var optionsId string
+		if i := strings.LastIndexByte(path, ','); i >= 0 {
+			optionsId = path[i+1:]
+			path = path[:i]
+			if _, err := model.ParseGuid(optionsId); err != nil {
+				return nil, fmt.Errorf("invalid ramdisk options object: %s", optionsId)
+			}
+		}
+		return model.NewRamdiskDevice(*parent, path, optionsId), nil
+	}
+	return nil, fmt.Errorf("unknown device: %s", s)
+}
+
+func parseFilePathSpec(spec string) (*model.DeviceDescriptor, string, error) {
+	end := strings.IndexByte(spec, ']')
+	if !strings.HasPrefix(spec, "[") || end < 0 {
+		return nil, "", fmt.Errorf("expected [<parent>]<path>: %s", spec)
+	}
+	path := spec[end+1:]
+	if path == "" {
+		return nil, "", fmt.Errorf("missing path: %s", spec)
+	}
+	parentSpec := spec[1:end]
+	switch strings.ToLower(parentSpec) {
+	case "boot":
+		return &model.NewBootDevice().DeviceDescriptor, path, nil
+	case "locate":
+		return &model.NewLocateDevice("").DeviceDescriptor, path, nil
+	}
+	parent, err := parsePartitionSpec(parentSpec)
+	if err != nil {
+		return nil, "", err
+	}
+	return &parent.DeviceDescriptor, path, nil
+}
+
+func parsePartitionSpec(spec string) (*model.Device, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected gpt:<disk>:<partition> or mbr:<signature>:<offset>: %s", spec)
+	}
+	switch strings.ToLower(parts[0]) {
+	case "gpt":
+		diskId, err := model.ParseGuid(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		partitionId, err := model.ParseGuid(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		return model.NewGptPartitionDevice(diskId, partitionId), nil
+	case "mbr":
+		signature, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(parts[1]), "0x"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mbr disk signature: %s", parts[1])
+		}
+		offset, err := strconv.ParseUint(parts[2], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mbr partition offset: %s", parts[2])
+		}
+		return model.NewMbrPartitionDevice(uint32(signature), offset), nil
+	}
+	return nil, fmt.Errorf("unknown partition style: %s", parts[0])
+}
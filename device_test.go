@@ -0,0 +1,218 @@
+package go_bcdedit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/jc-lab/go-bcdedit/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testDiskId      = "{5f5d0f7e-2f4b-4c43-9d2e-8a1f6b3c7d01}"
+	testPartitionId = "{c12a7328-f81f-11d2-ba4b-00a0c93ec93b}"
+	testOptionsId   = "{7619dcc8-fafe-11d9-b411-000476eba25f}"
+)
+
+func mustParseGuid(t *testing.T, s string) model.Guid {
+	t.Helper()
+	guid, err := model.ParseGuid(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return guid
+}
+
+func TestDeviceMarshalRoundTrip(t *testing.T) {
+	diskId := mustParseGuid(t, testDiskId)
+	partitionId := mustParseGuid(t, testPartitionId)
+	gpt := model.NewGptPartitionDevice(diskId, partitionId).DeviceDescriptor
+	mbr := model.NewMbrPartitionDevice(0x1a2b3c4d, 1048576).DeviceDescriptor
+	boot := model.NewBootDevice().DeviceDescriptor
+	locate := model.NewLocateDevice("").DeviceDescriptor
+
+	tests := []struct {
+		name   string
+		device *model.Device
+	}{
+		{"boot", model.NewBootDevice()},
+		{"gpt partition", model.NewGptPartitionDevice(diskId, partitionId)},
+		{"mbr partition", model.NewMbrPartitionDevice(0x1a2b3c4d, 1048576)},
+		{"locate", model.NewLocateDevice("")},
+		{"locate path", model.NewLocateDevice(`\windows`)},
+		{"ramdisk on boot", model.NewRamdiskDevice(boot, `\sources\boot.wim`, testOptionsId)},
+		{"ramdisk on gpt", model.NewRamdiskDevice(gpt, `\sources\boot.wim`, testOptionsId)},
+		{"ramdisk without options", model.NewRamdiskDevice(mbr, `\boot.wim`, "")},
+		{"file on mbr", model.NewFileDevice(mbr, `\boot\boot.sdi`)},
+		{"file on locate", model.NewFileDevice(locate, `\boot\boot.sdi`)},
+		{"vhd on gpt", model.NewVhdDevice(gpt, `\vm\windows.vhdx`)},
+		{"vhd on boot", model.NewVhdDevice(boot, `\windows.vhd`)},
+	}
+	for _, test := range tests {
+		raw, err := MarshalDevice(test.device)
+		if err != nil {
+			t.Errorf("%s: marshal: %v", test.name, err)
+			continue
+		}
+		decoded, err := ParseDevice(raw)
+		if err != nil {
+			t.Errorf("%s: parse: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, test.device) {
+			t.Errorf("%s: decoded %s, want %s", test.name, decoded, test.device)
+		}
+		again, err := MarshalDevice(decoded)
+		if err != nil {
+			t.Errorf("%s: marshal decoded: %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(again, raw) {
+			t.Errorf("%s: re-encoded %x, want %x", test.name, again, raw)
+		}
+	}
+}
+
+func TestParseDeviceStringRoundTrip(t *testing.T) {
+	tests := []string{
+		"boot",
+		"partition=gpt:" + testDiskId + ":" + testPartitionId,
+		"partition=mbr:1a2b3c4d:1048576",
+		"locate",
+		`locate=\windows`,
+		`ramdisk=[boot]\sources\boot.wim,` + testOptionsId,
+		`ramdisk=[gpt:` + testDiskId + ":" + testPartitionId + `]\sources\boot.wim,` + testOptionsId,
+		`ramdisk=[mbr:1a2b3c4d:1048576]\boot.wim`,
+		`file=[mbr:1a2b3c4d:1048576]\boot\boot.sdi`,
+		`file=[locate]\boot\boot.sdi`,
+		`vhd=[gpt:` + testDiskId + ":" + testPartitionId + `]\vm\windows.vhdx`,
+		`vhd=[boot]\windows.vhd`,
+	}
+	for _, s := range tests {
+		device, err := ParseDeviceString(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if got := device.String(); got != s {
+			t.Errorf("%s: formatted as %s", s, got)
+		}
+		raw, err := MarshalDevice(device)
+		if err != nil {
+			t.Errorf("%s: marshal: %v", s, err)
+			continue
+		}
+		decoded, err := ParseDevice(raw)
+		if err != nil {
+			t.Errorf("%s: parse: %v", s, err)
+			continue
+		}
+		if got := decoded.String(); got != s {
+			t.Errorf("%s: decoded as %s", s, got)
+		}
+	}
+}
+
+// deviceFixtures are Device-format element values laid out byte by byte
+// after the layout described in device.go, with the strings bcdedit shows
+// for them. GUIDs are stored with their first three fields little-endian.
+var deviceFixtures = []struct {
+	device string
+	raw    []string // hex, one line per field
+}{
+	{"boot", []string{
+		"00000000000000000000000000000000", // no options object
+		"00000000 05000000 00000000 18000000",
+		"0000000000000000",
+	}},
+	{"partition=gpt:" + testDiskId + ":" + testPartitionId, []string{
+		"00000000000000000000000000000000",
+		"00000000 06000000 00000000 48000000",
+		"28732ac1 1ff8 d211 ba4b00a0c93ec93b", // partition GUID
+		"00000000",                            // hard disk
+		"00000000",                            // GPT
+		"7e0f5d5f 4b2f 434c 9d2e8a1f6b3c7d01", // disk GUID
+		"00000000000000000000000000000000",
+	}},
+	{"partition=mbr:1a2b3c4d:1048576", []string{
+		"00000000000000000000000000000000",
+		"00000000 06000000 00000000 48000000",
+		"0000100000000000 0000000000000000", // partition offset
+		"00000000",                          // hard disk
+		"01000000",                          // MBR
+		"4d3c2b1a",                          // disk signature
+		"00000000000000000000000000000000000000000000000000000000",
+	}},
+	{`ramdisk=[boot]\sources\boot.wim,` + testOptionsId, []string{
+		"c8dc1976 fefa d911 b411000476eba25f", // {ramdiskoptions}-like options object
+		"00000000 00000000 00000000 50000000",
+		"03000000",                                             // ramdisk
+		"00000000 05000000 00000000 18000000 0000000000000000", // boot parent
+		"5c0073006f0075007200630065007300 5c0062006f006f0074002e00770069006d00 0000",
+	}},
+	{`vhd=[gpt:` + testDiskId + ":" + testPartitionId + `]\vm\windows.vhdx`, []string{
+		"00000000000000000000000000000000",
+		"00000000 00000000 00000000 7e000000",
+		"06000000", // virtual hard disk
+		"00000000 06000000 00000000 48000000",
+		"28732ac1 1ff8 d211 ba4b00a0c93ec93b 00000000 00000000 7e0f5d5f 4b2f 434c 9d2e8a1f6b3c7d01",
+		"00000000000000000000000000000000",
+		"5c0076006d005c00 770069006e0064006f00770073002e007600680064007800 0000",
+	}},
+}
+
+func fixtureBytes(t *testing.T, lines []string) []byte {
+	t.Helper()
+	var raw []byte
+	for _, line := range lines {
+		b, err := hex.DecodeString(strings.ReplaceAll(line, " ", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw = append(raw, b...)
+	}
+	return raw
+}
+
+func TestParseDeviceFixtures(t *testing.T) {
+	for _, fixture := range deviceFixtures {
+		raw := fixtureBytes(t, fixture.raw)
+		if size := binary.LittleEndian.Uint32(raw[0x1C:]); deviceOptionsSize+int(size) != len(raw) {
+			t.Fatalf("%s: fixture of %d bytes has descriptor size %d", fixture.device, len(raw), size)
+		}
+		device, err := ParseDevice(raw)
+		if err != nil {
+			t.Errorf("%s: %v", fixture.device, err)
+			continue
+		}
+		if got := device.String(); got != fixture.device {
+			t.Errorf("decoded as %s, want %s", got, fixture.device)
+		}
+	}
+}
+
+func TestMarshalDeviceFixtures(t *testing.T) {
+	for _, fixture := range deviceFixtures {
+		raw := fixtureBytes(t, fixture.raw)
+		decoded, err := ParseDevice(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", fixture.device, err)
+		}
+		parsed, err := ParseDeviceString(fixture.device)
+		if err != nil {
+			t.Fatalf("%s: %v", fixture.device, err)
+		}
+		for _, device := range []*model.Device{decoded, parsed} {
+			got, err := MarshalDevice(device)
+			if err != nil {
+				t.Errorf("%s: marshal: %v", fixture.device, err)
+				continue
+			}
+			if !bytes.Equal(got, raw) {
+				t.Errorf("%s: marshaled as\n%x\nwant\n%x", fixture.device, got, raw)
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package go_bcdedit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/pkg/errors"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Diff returns the operations that turn store a into store b, ordered by
+// object id and element type.
+func Diff(a, b Bcdedit) (*model.Patch, error) {
+	aObjects, err := enumerateByLowerId(a)
+	if err != nil {
+		return nil, err
+	}
+	bObjects, err := enumerateByLowerId(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for id := range aObjects {
+		ids = append(ids, id)
+	}
+	for id := range bObjects {
+		if _, ok := aObjects[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	patch := &model.Patch{
+		Operations: []*model.PatchOperation{},
+	}
+	for _, id := range ids {
+		aObject, bObject := aObjects[id], bObjects[id]
+		switch {
+		case bObject == nil:
+			description := aObject.GetDescription()
+			patch.Operations = append(patch.Operations, diffElements(aObject.GetId(), description, aObject.GetElements(), nil)...)
+			patch.Operations = append(patch.Operations, &model.PatchOperation{
+				Op:             model.PatchRemoveObject,
+				ObjectId:       aObject.GetId(),
+				OldDescription: &description,
+			})
+		case aObject == nil:
+			description := bObject.GetDescription()
+			patch.Operations = append(patch.Operations, &model.PatchOperation{
+				Op:          model.PatchAddObject,
+				ObjectId:    bObject.GetId(),
+				Description: &description,
+			})
+			patch.Operations = append(patch.Operations, diffElements(bObject.GetId(), description, nil, bObject.GetElements())...)
+		default:
+			oldDescription, description := aObject.GetDescription(), bObject.GetDescription()
+			if oldDescription != description {
+				patch.Operations = append(patch.Operations, &model.PatchOperation{
+					Op:             model.PatchSetType,
+					ObjectId:       aObject.GetId(),
+					OldDescription: &oldDescription,
+					Description:    &description,
+				})
+			}
+			patch.Operations = append(patch.Operations, diffElements(aObject.GetId(), description, aObject.GetElements(), bObject.GetElements())...)
+		}
+	}
+	return patch, nil
+}
+
+func enumerateByLowerId(bcd Bcdedit) (map[string]BcdObject, error) {
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[string]BcdObject)
+	for id, object := range objects {
+		byId[strings.ToLower(id)] = object
+	}
+	return byId, nil
+}
+
+func diffElements(objectId string, description model.BcdDescription, a, b map[model.ElementType]BcdElement) []*model.PatchOperation {
+	var keys []model.ElementType
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	application := description.ApplicationType()
+	var operations []*model.PatchOperation
+	for _, key := range keys {
+		operation := &model.PatchOperation{
+			ObjectId: objectId,
+			Element:  key.String(),
+		}
+		if option := model.FindOptionByType(key, application); option != nil {
+			operation.Name = option.Name
+		}
+		aElement, inA := a[key]
+		bElement, inB := b[key]
+		switch {
+		case !inB:
+			operation.Op = model.PatchRemoveElement
+		case !inA:
+			operation.Op = model.PatchAddElement
+		case aElement.GetType() != bElement.GetType() || !bytes.Equal(aElement.GetRaw(), bElement.GetRaw()):
+			operation.Op = model.PatchReplaceElement
+		default:
+			continue
+		}
+		if inA {
+			operation.Old = elementToJson(key, aElement)
+			operation.OldValue = FormatElementValue(key, application, aElement)
+		}
+		if inB {
+			operation.New = elementToJson(key, bElement)
+			operation.NewValue = FormatElementValue(key, application, bElement)
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+func elementToJson(key model.ElementType, element BcdElement) *model.BcdElement {
+	return (&HiveBcdElement{Key: key, Type: element.GetType(), Raw: element.GetRaw()}).ToJson()
+}
+
+// ApplyPatch applies the operations of a patch made by Diff. It fails with
+// a conflict if the store does not hold the old values recorded in the
+// patch; wrap it in a transaction to leave the store untouched then.
+func ApplyPatch(bcd Bcdedit, patch *model.Patch) error {
+	for i, operation := range patch.Operations {
+		if err := applyPatchOperation(bcd, operation); err != nil {
+			return errors.Wrapf(err, "operation %d (%s %s)", i, operation.Op, operation.ObjectId)
+		}
+	}
+	return nil
+}
+
+func applyPatchOperation(bcd Bcdedit, operation *model.PatchOperation) error {
+	switch operation.Op {
+	case model.PatchAddObject:
+		if operation.Description == nil {
+			return errors.New("missing description")
+		}
+		_, err := bcd.GetObject(operation.ObjectId)
+		if err == nil {
+			return errors.New("conflict: object already exists")
+		}
+		if !errors.Is(err, ErrObjectNotFound) {
+			return err
+		}
+		_, err = bcd.UpsertObject(operation.ObjectId, *operation.Description)
+		return err
+
+	case model.PatchRemoveObject:
+		object, err := bcd.GetObject(operation.ObjectId)
+		if err != nil {
+			return err
+		}
+		if operation.OldDescription != nil && object.GetDescription() != *operation.OldDescription {
+			return fmt.Errorf("conflict: object type is %08x", uint32(object.GetDescription()))
+		}
+		return bcd.DeleteObject(operation.ObjectId)
+
+	case model.PatchSetType:
+		if operation.Description == nil {
+			return errors.New("missing description")
+		}
+		object, err := bcd.GetObject(operation.ObjectId)
+		if err != nil {
+			return err
+		}
+		if operation.OldDescription != nil && object.GetDescription() != *operation.OldDescription {
+			return fmt.Errorf("conflict: object type is %08x", uint32(object.GetDescription()))
+		}
+		_, err = bcd.UpsertObject(operation.ObjectId, *operation.Description)
+		return err
+	}
+
+	key, err := model.ParseElementType(operation.Element)
+	if err != nil {
+		return err
+	}
+	object, err := bcd.GetObject(operation.ObjectId)
+	if err != nil {
+		return err
+	}
+	current, exists := object.GetElements()[key]
+
+	switch operation.Op {
+	case model.PatchAddElement, model.PatchReplaceElement:
+		if operation.New == nil {
+			return errors.New("missing new value")
+		}
+		if operation.Op == model.PatchAddElement && exists {
+			return fmt.Errorf("conflict: element %s already exists", key)
+		}
+		if operation.Op == model.PatchReplaceElement {
+			if err = checkOldElement(key, current, exists, operation.Old); err != nil {
+				return err
+			}
+		}
+		typ, raw, err := elementFromJson(key, operation.New)
+		if err != nil {
+			return err
+		}
+		_, err = object.SetElement(key, typ, raw)
+		return err
+
+	case model.PatchRemoveElement:
+		if err = checkOldElement(key, current, exists, operation.Old); err != nil {
+			return err
+		}
+		return object.DeleteElement(key)
+	}
+	return fmt.Errorf("unknown patch op: %q", operation.Op)
+}
+
+func checkOldElement(key model.ElementType, current BcdElement, exists bool, old *model.BcdElement) error {
+	if !exists {
+		return fmt.Errorf("conflict: element %s does not exist", key)
+	}
+	if old == nil {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(old.Raw)
+	if err != nil {
+		return err
+	}
+	if current.GetType() != ValueTypeFromJson(old.Type) || !bytes.Equal(current.GetRaw(), raw) {
+		return fmt.Errorf("conflict: element %s has changed", key)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package go_bcdedit
+
+import (
+	"encoding/json"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hiveutil"
+	"slices"
+	"testing"
+)
+
+// newChangedStore returns the test store with the loader described
+// differently, a second loader and no timeout.
+func newChangedStore(t *testing.T) Bcdedit {
+	t.Helper()
+	bcd := newTestStore(t)
+	setDescription(t, bcd, testLoaderId, "Windows 11")
+	if _, err := bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err := mustGetObject(t, bcd, "{bootmgr}").DeleteElement(model.ElementTimeout); err != nil {
+		t.Fatal(err)
+	}
+	return bcd
+}
+
+func patchOps(patch *model.Patch) []string {
+	var ops []string
+	for _, operation := range patch.Operations {
+		ops = append(ops, string(operation.Op)+" "+operation.ObjectId+" "+operation.Element)
+	}
+	return ops
+}
+
+func TestDiff(t *testing.T) {
+	a := newTestStore(t)
+	defer a.Close()
+	b := newChangedStore(t)
+	defer b.Close()
+
+	patch, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"replace-element " + testLoaderId + " 12000004",
+		"add-object " + testOtherId + " ",
+		"remove-element " + BootMgrObjectId + " 25000004",
+	}
+	if got := patchOps(patch); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if operation := patch.Operations[0]; operation.OldValue != "Windows" || operation.NewValue != "Windows 11" || operation.Name != "description" {
+		t.Errorf("replace: %+v", operation)
+	}
+
+	// the patch survives JSON and turns a into b
+	data, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &model.Patch{}
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyPatch(a, decoded); err != nil {
+		t.Fatal(err)
+	}
+	compareStores(t, a, b)
+	if patch, err = Diff(a, b); err != nil || len(patch.Operations) != 0 {
+		t.Errorf("diff after patching: %q, %v", patchOps(patch), err)
+	}
+}
+
+func TestApplyPatchConflict(t *testing.T) {
+	a := newTestStore(t)
+	defer a.Close()
+	b := newChangedStore(t)
+	defer b.Close()
+	patch, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := newTestStore(t)
+	defer target.Close()
+	setDescription(t, target, testLoaderId, "Edited elsewhere")
+	if err = ApplyPatch(target, patch); err == nil {
+		t.Fatal("changed old value: expected a conflict")
+	}
+	if got := description(t, target, testLoaderId); got != "Edited elsewhere" {
+		t.Errorf("description %q after the conflict", got)
+	}
+
+	target = newTestStore(t)
+	defer target.Close()
+	if _, err = target.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyPatch(target, &model.Patch{Operations: patch.Operations[1:2]}); err == nil {
+		t.Error("adding an existing object: expected a conflict")
+	}
+	if err = ApplyPatch(target, &model.Patch{Operations: patch.Operations[2:]}); err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyPatch(target, &model.Patch{Operations: patch.Operations[2:]}); err == nil {
+		t.Error("removing a missing element: expected a conflict")
+	}
+}
+
+func TestApplyPatchAddOntoUnreadableObject(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	h := bcd.(*HiveBcdedit).Hive
+	objects, err := hiveutil.GetObjectsNode(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := h.NodeAddChild(objects, testOtherId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objectType := osloaderType
+	err = ApplyPatch(bcd, &model.Patch{Operations: []*model.PatchOperation{
+		{Op: model.PatchAddObject, ObjectId: testOtherId, Description: &objectType},
+	}})
+	if err == nil {
+		t.Error("adding over an unreadable object: expected an error")
+	}
+	if child, _ := hiveutil.FindChild(h, node, "Description"); child != 0 {
+		t.Error("the unreadable object was overwritten")
+	}
+}
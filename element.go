@@ -1,3 +1,219 @@
 package go_bcdedit
 
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"strconv"
+	"strings"
+)
+
 type ValueType int
+
+func checkFormat(key model.ElementType, format model.ElementFormat) error {
+	if key.Format() != format {
+		return fmt.Errorf("element %s is not a %s element", key, format)
+	}
+	return nil
+}
+
+func (e *HiveBcdElement) GetBoolean() (bool, error) {
+	if err := checkFormat(e.Key, model.ElementFormatBoolean); err != nil {
+		return false, err
+	}
+	switch e.Type {
+	case RegBinary, RegDword:
+		for _, b := range e.Raw {
+			if b != 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected boolean value type: %d", e.Type)
+}
+
+func (e *HiveBcdElement) GetInteger() (uint64, error) {
+	if err := checkFormat(e.Key, model.ElementFormatInteger); err != nil {
+		return 0, err
+	}
+	switch e.Type {
+	case RegBinary, RegDword, RegQword:
+		if len(e.Raw) > 8 {
+			return 0, fmt.Errorf("invalid integer length: %d", len(e.Raw))
+		}
+		var buf [8]byte
+		copy(buf[:], e.Raw)
+		return binary.LittleEndian.Uint64(buf[:]), nil
+	}
+	return 0, fmt.Errorf("unexpected integer value type: %d", e.Type)
+}
+
+func (e *HiveBcdElement) GetIntegerList() ([]uint64, error) {
+	if err := checkFormat(e.Key, model.ElementFormatIntegerList); err != nil {
+		return nil, err
+	}
+	if e.Type != RegBinary || len(e.Raw)%8 != 0 {
+		return nil, fmt.Errorf("invalid integer list: type=%d, length=%d", e.Type, len(e.Raw))
+	}
+	var values []uint64
+	for i := 0; i < len(e.Raw); i += 8 {
+		values = append(values, binary.LittleEndian.Uint64(e.Raw[i:]))
+	}
+	return values, nil
+}
+
+func (e *HiveBcdElement) GetObject() (string, error) {
+	if err := checkFormat(e.Key, model.ElementFormatObject); err != nil {
+		return "", err
+	}
+	return e.GetString()
+}
+
+func (e *HiveBcdElement) GetObjectList() ([]string, error) {
+	if err := checkFormat(e.Key, model.ElementFormatObjectList); err != nil {
+		return nil, err
+	}
+	return e.GetMultiStrings()
+}
+
+func (e *HiveBcdElement) GetDevice() (*model.Device, error) {
+	if err := checkFormat(e.Key, model.ElementFormatDevice); err != nil {
+		return nil, err
+	}
+	if e.Type != RegBinary {
+		return nil, fmt.Errorf("no RegBinary type: %d", e.Type)
+	}
+	return ParseDevice(e.Raw)
+}
+
+func (o *HiveBcdObject) SetString(key model.ElementType, value string) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatString); err != nil {
+		return nil, err
+	}
+	raw, err := StringToUtf16LE(value + "\x00")
+	if err != nil {
+		return nil, err
+	}
+	return o.SetElement(key, RegSz, raw)
+}
+
+func (o *HiveBcdObject) SetBoolean(key model.ElementType, value bool) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatBoolean); err != nil {
+		return nil, err
+	}
+	raw := []byte{0}
+	if value {
+		raw[0] = 1
+	}
+	return o.SetElement(key, RegBinary, raw)
+}
+
+func (o *HiveBcdObject) SetInteger(key model.ElementType, value uint64) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatInteger); err != nil {
+		return nil, err
+	}
+	return o.SetElement(key, RegBinary, binary.LittleEndian.AppendUint64(nil, value))
+}
+
+func (o *HiveBcdObject) SetIntegerList(key model.ElementType, values []uint64) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatIntegerList); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, 0, len(values)*8)
+	for _, value := range values {
+		raw = binary.LittleEndian.AppendUint64(raw, value)
+	}
+	return o.SetElement(key, RegBinary, raw)
+}
+
+func (o *HiveBcdObject) SetObject(key model.ElementType, objectId string) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatObject); err != nil {
+		return nil, err
+	}
+	raw, err := StringToUtf16LE(objectId + "\x00")
+	if err != nil {
+		return nil, err
+	}
+	return o.SetElement(key, RegSz, raw)
+}
+
+func (o *HiveBcdObject) SetObjectList(key model.ElementType, objectIds []string) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatObjectList); err != nil {
+		return nil, err
+	}
+	raw, err := StringsToMultiUtf16LE(objectIds)
+	if err != nil {
+		return nil, err
+	}
+	return o.SetElement(key, RegMultiSz, raw)
+}
+
+func (o *HiveBcdObject) SetDevice(key model.ElementType, device *model.Device) (BcdElement, error) {
+	if err := checkFormat(key, model.ElementFormatDevice); err != nil {
+		return nil, err
+	}
+	raw, err := MarshalDevice(device)
+	if err != nil {
+		return nil, err
+	}
+	return o.SetElement(key, RegBinary, raw)
+}
+
+// FormatElementValue decodes an element value for display: booleans as
+// Yes/No, integers in decimal or by their option value name (e.g. OptIn),
+// integer lists in hex and lists one item per line. Values that do not decode are shown as hex.
+func FormatElementValue(key model.ElementType, application model.ApplicationType, element BcdElement) string {
+	var lines []string
+	var err error
+	switch key.Format() {
+	case model.ElementFormatBoolean:
+		var value bool
+		if value, err = element.GetBoolean(); err == nil {
+			lines = append(lines, map[bool]string{true: "Yes", false: "No"}[value])
+		}
+	case model.ElementFormatInteger:
+		var value uint64
+		if value, err = element.GetInteger(); err == nil {
+			name := ""
+			if option := model.FindOptionByType(key, application); option != nil {
+				name = option.ValueName(value)
+			}
+			if name == "" {
+				name = strconv.FormatUint(value, 10)
+			}
+			lines = append(lines, name)
+		}
+	case model.ElementFormatIntegerList:
+		var values []uint64
+		if values, err = element.GetIntegerList(); err == nil {
+			for _, value := range values {
+				lines = append(lines, fmt.Sprintf("0x%x", value))
+			}
+		}
+	case model.ElementFormatObject:
+		var value string
+		if value, err = element.GetObject(); err == nil {
+			lines = append(lines, value)
+		}
+	case model.ElementFormatObjectList:
+		lines, err = element.GetObjectList()
+	case model.ElementFormatDevice:
+		var device *model.Device
+		if device, err = element.GetDevice(); err == nil {
+			lines = append(lines, device.String())
+		}
+	case model.ElementFormatString:
+		var value string
+		if _, value, err = Utf16LEToString(element.GetRaw()); err == nil {
+			lines = append(lines, value)
+		}
+	default:
+		err = fmt.Errorf("element %s has unknown format", key)
+	}
+	if err != nil {
+		return hex.EncodeToString(element.GetRaw())
+	}
+	return strings.Join(lines, "\n")
+}
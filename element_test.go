@@ -0,0 +1,93 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"reflect"
+	"testing"
+)
+
+const (
+	testBootDebug       = model.ElementType(0x16000010)
+	testAllowedSettings = model.ElementType(0x17000077)
+)
+
+func TestTypedElements(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	object := mustGetObject(t, bcd, testLoaderId)
+	device := model.NewBootDevice()
+	mustSet(t)(object.SetBoolean(testBootDebug, true))
+	mustSet(t)(object.SetIntegerList(testAllowedSettings, []uint64{0x15000075, 0x1}))
+	mustSet(t)(object.SetObject(model.ElementResumeObject, testOtherId))
+	mustSet(t)(object.SetObjectList(model.ElementInheritedObjects, []string{"{bootloadersettings}", testOtherId}))
+	mustSet(t)(object.SetDevice(model.ElementOsDevice, device))
+
+	elements := mustGetObject(t, bcd, testLoaderId).GetElements()
+	if value, err := elements[testBootDebug].GetBoolean(); err != nil || !value {
+		t.Errorf("boolean: %v, %v", value, err)
+	}
+	if values, err := elements[testAllowedSettings].GetIntegerList(); err != nil || !reflect.DeepEqual(values, []uint64{0x15000075, 0x1}) {
+		t.Errorf("integer list: %x, %v", values, err)
+	}
+	if value, err := elements[model.ElementResumeObject].GetObject(); err != nil || value != testOtherId {
+		t.Errorf("object: %s, %v", value, err)
+	}
+	if values, err := elements[model.ElementInheritedObjects].GetObjectList(); err != nil || !reflect.DeepEqual(values, []string{"{bootloadersettings}", testOtherId}) {
+		t.Errorf("object list: %q, %v", values, err)
+	}
+	if value, err := elements[model.ElementOsDevice].GetDevice(); err != nil || value.String() != device.String() {
+		t.Errorf("device: %v, %v", value, err)
+	}
+
+	manager := mustGetObject(t, bcd, "{bootmgr}").GetElements()
+	if value, err := manager[model.ElementTimeout].GetInteger(); err != nil || value != 30 {
+		t.Errorf("integer: %d, %v", value, err)
+	}
+}
+
+func TestTypedElementFormats(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	object := mustGetObject(t, bcd, testLoaderId)
+	if _, err := object.SetInteger(model.ElementDescription, 1); err == nil {
+		t.Error("integer into a string element: expected an error")
+	}
+	if _, err := object.SetString(model.ElementTimeout, "30"); err == nil {
+		t.Error("string into an integer element: expected an error")
+	}
+	if _, err := object.SetBoolean(model.ElementOsDevice, true); err == nil {
+		t.Error("boolean into a device element: expected an error")
+	}
+
+	description := object.GetElements()[model.ElementDescription]
+	if _, err := description.GetInteger(); err == nil {
+		t.Error("integer from a string element: expected an error")
+	}
+	if _, err := description.GetObjectList(); err == nil {
+		t.Error("object list from a string element: expected an error")
+	}
+}
+
+func TestTypedElementStoredTypes(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	object := mustGetObject(t, bcd, testLoaderId)
+	// Values written by other tools may use REG_DWORD instead of REG_BINARY.
+	mustSet(t)(object.SetElement(testBootDebug, RegDword, []byte{1, 0, 0, 0}))
+	mustSet(t)(object.SetElement(model.ElementTimeout, RegDword, []byte{5, 0, 0, 0}))
+	mustSet(t)(object.SetElement(testAllowedSettings, RegBinary, []byte{1, 2, 3}))
+
+	elements := object.GetElements()
+	if value, err := elements[testBootDebug].GetBoolean(); err != nil || !value {
+		t.Errorf("dword boolean: %v, %v", value, err)
+	}
+	if value, err := elements[model.ElementTimeout].GetInteger(); err != nil || value != 5 {
+		t.Errorf("dword integer: %d, %v", value, err)
+	}
+	if _, err := elements[testAllowedSettings].GetIntegerList(); err == nil {
+		t.Error("truncated integer list: expected an error")
+	}
+}
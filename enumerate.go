@@ -0,0 +1,127 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"strings"
+)
+
+// EnumType selects objects by type like the bcdedit /enum type argument.
+type EnumType string
+
+const (
+	EnumAll      EnumType = "all"
+	EnumActive   EnumType = "active"   // {bootmgr} and the entries in its display order
+	EnumFirmware EnumType = "firmware" // firmware applications, including {fwbootmgr}
+	EnumBootapp  EnumType = "bootapp"  // Windows boot environment applications
+	EnumBootmgr  EnumType = "bootmgr"
+	EnumOsloader EnumType = "osloader"
+	EnumResume   EnumType = "resume"
+	EnumInherit  EnumType = "inherit"
+	EnumDevice   EnumType = "device"
+)
+
+var EnumTypes = []EnumType{
+	EnumAll, EnumActive, EnumFirmware, EnumBootapp, EnumBootmgr,
+	EnumOsloader, EnumResume, EnumInherit, EnumDevice,
+}
+
+// ParseEnumType returns the EnumType named s, ignoring case.
+func ParseEnumType(s string) (EnumType, bool) {
+	for _, t := range EnumTypes {
+		if strings.EqualFold(string(t), s) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// EnumerateOptions selects the objects EnumerateObjects returns: those
+// matching any of Types or Ids. Without either every object is returned.
+type EnumerateOptions struct {
+	Types []EnumType
+	Ids   []string // object ids or aliases, each of which must exist
+}
+
+func EnumerateObjects(bcd Bcdedit, options EnumerateOptions) (map[string]BcdObject, error) {
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		return nil, err
+	}
+	if len(options.Types) == 0 && len(options.Ids) == 0 {
+		return objects, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range options.Ids {
+		resolved, err := ResolveExistingObjectId(bcd, id)
+		if err != nil {
+			return nil, err
+		}
+		selected[strings.ToLower(resolved)] = true
+	}
+	for _, t := range options.Types {
+		if t != EnumActive {
+			continue
+		}
+		manager, ok := findObject(objects, BootMgrObjectId)
+		if !ok {
+			continue
+		}
+		selected[strings.ToLower(manager.GetId())] = true
+		if element, ok := manager.GetElements()[model.ElementDisplayOrder]; ok {
+			ids, err := element.GetObjectList()
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids {
+				selected[strings.ToLower(id)] = true
+			}
+		}
+	}
+
+	filtered := make(map[string]BcdObject)
+	for id, object := range objects {
+		if selected[strings.ToLower(id)] || matchesEnumTypes(object.GetDescription(), options.Types) {
+			filtered[id] = object
+		}
+	}
+	return filtered, nil
+}
+
+func findObject(objects map[string]BcdObject, id string) (BcdObject, bool) {
+	for objectId, object := range objects {
+		if strings.EqualFold(objectId, id) {
+			return object, true
+		}
+	}
+	return nil, false
+}
+
+func matchesEnumTypes(description model.BcdDescription, types []EnumType) bool {
+	application := description.ObjectType() == model.ObjectApplication
+	for _, t := range types {
+		var match bool
+		switch t {
+		case EnumAll:
+			match = true
+		case EnumFirmware:
+			match = application && description.ObjectSubType() == model.FirmwareApplication
+		case EnumBootapp:
+			match = application && description.ObjectSubType() == model.WindowsBootApplication
+		case EnumBootmgr:
+			match = application && description.ApplicationType() == model.ApplicationBootmgr
+		case EnumOsloader:
+			match = application && description.ApplicationType() == model.ApplicationOsloader
+		case EnumResume:
+			match = application && description.ApplicationType() == model.ApplicationResume
+		case EnumInherit:
+			match = description.ObjectType() == model.ObjectInherit
+		case EnumDevice:
+			match = description.ObjectType() == model.ObjectDevice
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	testDeviceId   = "{0d0d0d0d-0000-4000-8000-000000000000}"
+	testFirmwareId = "{0f0f0f0f-0000-4000-8000-000000000000}"
+)
+
+// newEnumerateStore returns the test store with one object of each kind
+// and a loader outside the display order.
+func newEnumerateStore(t *testing.T) Bcdedit {
+	t.Helper()
+	bcd := newTestStore(t)
+	for id, objectType := range map[string]model.BcdDescription{
+		testOtherId:                              osloaderType,
+		testSettingsId:                           inheritType,
+		testDeviceId:                             model.BcdDescriptionFrom(model.ObjectDevice, 0, 0),
+		testFirmwareId:                           model.BcdDescriptionFrom(model.ObjectApplication, model.FirmwareApplication, model.ApplicationBootapp),
+		FwBootMgrObjectId:                        model.BcdDescriptionFrom(model.ObjectApplication, model.FirmwareApplication, model.ApplicationFwbootmgr),
+		"{0e0e0e0e-0000-4000-8000-000000000000}": model.BcdDescriptionFrom(model.ObjectApplication, model.WindowsBootApplication, model.ApplicationResume),
+	} {
+		if _, err := bcd.UpsertObject(id, objectType); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bcd
+}
+
+func TestEnumerateObjects(t *testing.T) {
+	bcd := newEnumerateStore(t)
+	defer bcd.Close()
+
+	tests := []struct {
+		options EnumerateOptions
+		want    []string
+	}{
+		{EnumerateOptions{}, []string{"{0d0d0d0d", "{0e0e0e0e", "{0f0f0f0f", "{11111111", "{66666666", "{6efb52bf", "{9dea862c", "{a5a30fa2"}},
+		{EnumerateOptions{Types: []EnumType{EnumAll}}, []string{"{0d0d0d0d", "{0e0e0e0e", "{0f0f0f0f", "{11111111", "{66666666", "{6efb52bf", "{9dea862c", "{a5a30fa2"}},
+		{EnumerateOptions{Types: []EnumType{EnumActive}}, []string{"{11111111", "{9dea862c"}},
+		{EnumerateOptions{Types: []EnumType{EnumFirmware}}, []string{"{0f0f0f0f", "{a5a30fa2"}},
+		{EnumerateOptions{Types: []EnumType{EnumBootapp}}, []string{"{0e0e0e0e", "{11111111", "{66666666", "{9dea862c"}},
+		{EnumerateOptions{Types: []EnumType{EnumBootmgr}}, []string{"{9dea862c"}},
+		{EnumerateOptions{Types: []EnumType{EnumOsloader}}, []string{"{11111111", "{66666666"}},
+		{EnumerateOptions{Types: []EnumType{EnumResume}}, []string{"{0e0e0e0e"}},
+		{EnumerateOptions{Types: []EnumType{EnumInherit}}, []string{"{6efb52bf"}},
+		{EnumerateOptions{Types: []EnumType{EnumDevice}}, []string{"{0d0d0d0d"}},
+		{EnumerateOptions{Types: []EnumType{EnumDevice, EnumResume}}, []string{"{0d0d0d0d", "{0e0e0e0e"}},
+		{EnumerateOptions{Ids: []string{"{bootloadersettings}", strings.ToUpper(testOtherId)}}, []string{"{66666666", "{6efb52bf"}},
+		{EnumerateOptions{Types: []EnumType{EnumDevice}, Ids: []string{"{default}"}}, []string{"{0d0d0d0d", "{11111111"}},
+	}
+	for _, test := range tests {
+		objects, err := EnumerateObjects(bcd, test.options)
+		if err != nil {
+			t.Errorf("%+v: %v", test.options, err)
+			continue
+		}
+		var got []string
+		for id := range objects {
+			got = append(got, id[:9])
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%+v: got %v, want %v", test.options, got, test.want)
+		}
+	}
+
+	if _, err := EnumerateObjects(bcd, EnumerateOptions{Ids: []string{"{0c0c0c0c-0000-4000-8000-000000000000}"}}); err == nil {
+		t.Error("missing id: expected an error")
+	}
+}
+
+func TestParseEnumType(t *testing.T) {
+	if got, ok := ParseEnumType("OSLOADER"); !ok || got != EnumOsloader {
+		t.Errorf("got %s, %t", got, ok)
+	}
+	if _, ok := ParseEnumType("{bootmgr}"); ok {
+		t.Error("{bootmgr} is not a type")
+	}
+}
@@ -3,7 +3,6 @@ module github.com/jc-lab/go-bcdedit
 go 1.22.7
 
 require (
-	github.com/gabriel-samfira/go-hivex v0.0.0-20190725123041-b40bc95a7ced // indirect
-	github.com/pkg/errors v0.9.1 // indirect
-	golang.org/x/text v0.18.0 // indirect
+	github.com/pkg/errors v0.9.1
+	gopkg.in/yaml.v3 v3.0.1
 )
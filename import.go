@@ -0,0 +1,185 @@
+package go_bcdedit
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/internal/bcdtemplate"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/regf"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ExportStore writes a copy of the store to file. Hive backed stores are
+// serialized as they are, others are rebuilt object by object into a new
+// hive first. The file is replaced atomically, so a failed export never
+// leaves a partial backup behind.
+func ExportStore(bcd Bcdedit, file string) error {
+	if syncBcd, ok := bcd.(*SyncBcdedit); ok {
+		syncBcd.mu.RLock()
+		defer syncBcd.mu.RUnlock()
+		return ExportStore(syncBcd.bcd, file)
+	}
+
+	if hiveBcd, ok := bcd.(*HiveBcdedit); ok {
+		if serializer, ok := hiveBcd.Hive.(interface{ Bytes() ([]byte, error) }); ok {
+			data, err := serializer.Bytes()
+			if err != nil {
+				return err
+			}
+			return regf.WriteFileAtomic(file, data)
+		}
+	}
+
+	h, err := regf.Parse(bcdtemplate.EMPTY, regf.WRITE)
+	if err != nil {
+		return err
+	}
+	// not Writable, so that Close does not try to commit the file-less hive
+	dst := &HiveBcdedit{Hive: h}
+	defer dst.Close()
+	if err = ImportStore(dst, bcd, true); err != nil {
+		return err
+	}
+	data, err := h.Bytes()
+	if err != nil {
+		return err
+	}
+	return regf.WriteFileAtomic(file, data)
+}
+
+// ImportStore replaces the objects of bcd with those of src like bcdedit
+// /import: every object is deleted or overwritten, except firmware boot
+// entries missing from src, which live in NVRAM on a real system and are
+// only removed when clean is set.
+func ImportStore(bcd Bcdedit, src Bcdedit, clean bool) error {
+	imported, err := src.Enumerate("all")
+	if err != nil {
+		return err
+	}
+	existing, err := bcd.Enumerate("all")
+	if err != nil {
+		return err
+	}
+	importedIds := make(map[string]bool)
+	for id := range imported {
+		importedIds[strings.ToLower(id)] = true
+	}
+
+	for id, object := range existing {
+		if importedIds[strings.ToLower(id)] {
+			// recreated below so that no stale element survives
+		} else if !clean && IsFirmwareEntry(object.GetDescription()) {
+			continue
+		}
+		if err = bcd.DeleteObject(id); err != nil {
+			return err
+		}
+	}
+
+	for id, src := range imported {
+		object, err := bcd.UpsertObject(id, src.GetDescription())
+		if err != nil {
+			return err
+		}
+		if err = copyElements(object, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportJson replaces the objects of bcd with those of an /enum all --json
+// response, the same way ImportStore does.
+func ImportJson(bcd Bcdedit, response *model.EnumerateResponse, clean bool) error {
+	src, err := StoreFromJson(response)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return ImportStore(bcd, src, clean)
+}
+
+// StoreFromJson builds a memory store holding the objects of an /enum all
+// --json response. Element values are taken from the raw bytes with their
+// recorded value type; the decoded fields are only used when raw is empty.
+func StoreFromJson(response *model.EnumerateResponse) (Bcdedit, error) {
+	bcd, err := CreateMemoryStore()
+	if err != nil {
+		return nil, err
+	}
+	for id, jsonObject := range response.Objects {
+		object, err := bcd.UpsertObject(id, jsonObject.Description)
+		if err != nil {
+			bcd.Close()
+			return nil, errors.Wrapf(err, "object %s", id)
+		}
+		for name, jsonElement := range jsonObject.Elements {
+			key, err := model.ParseElementType(name)
+			if err != nil {
+				bcd.Close()
+				return nil, errors.Wrapf(err, "object %s", id)
+			}
+			typ, raw, err := elementFromJson(key, jsonElement)
+			if err != nil {
+				bcd.Close()
+				return nil, errors.Wrapf(err, "object %s element %s", id, key)
+			}
+			if _, err = object.SetElement(key, typ, raw); err != nil {
+				bcd.Close()
+				return nil, errors.Wrapf(err, "object %s element %s", id, key)
+			}
+		}
+	}
+	return bcd, nil
+}
+
+func elementFromJson(key model.ElementType, element *model.BcdElement) (ValueType, []byte, error) {
+	typ := ValueTypeFromJson(element.Type)
+	if typ == RegNone && element.Type != model.RegNone {
+		return 0, nil, fmt.Errorf("unknown value type: %q", element.Type)
+	}
+	if element.Raw != "" {
+		raw, err := base64.StdEncoding.DecodeString(element.Raw)
+		return typ, raw, err
+	}
+
+	switch {
+	case typ == RegSz && element.ValueSz != "":
+		raw, err := StringToUtf16LE(element.ValueSz + "\x00")
+		return typ, raw, err
+	case typ == RegMultiSz && element.ValueMultiSz != nil:
+		raw, err := StringsToMultiUtf16LE(element.ValueMultiSz)
+		return typ, raw, err
+	case typ == RegDword && element.ValueDword != nil:
+		return typ, binary.LittleEndian.AppendUint32(nil, *element.ValueDword), nil
+	case typ == RegBinary && element.ValueDevice != nil && key.Format() == model.ElementFormatDevice:
+		raw, err := MarshalDevice(element.ValueDevice)
+		return typ, raw, err
+	}
+	return typ, []byte{}, nil
+}
+
+// IsFirmwareEntry reports whether objects of this type are firmware boot
+// entries (e.g. a USB or network boot option) rather than a boot manager.
+func IsFirmwareEntry(description model.BcdDescription) bool {
+	if description.ObjectType() != model.ObjectApplication || description.ObjectSubType() != model.FirmwareApplication {
+		return false
+	}
+	switch description.ApplicationType() {
+	case model.ApplicationFwbootmgr, model.ApplicationBootmgr:
+		return false
+	}
+	return true
+}
+
+func copyElements(dst BcdObject, src BcdObject) error {
+	for key, element := range src.GetElements() {
+		_, err := dst.SetElement(key, element.GetType(), element.GetRaw())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package go_bcdedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/jc-lab/go-bcdedit/model"
+	"path/filepath"
+	"testing"
+)
+
+// compareStores checks that two stores hold the same objects with the same
+// element values.
+func compareStores(t *testing.T, got Bcdedit, want Bcdedit) {
+	t.Helper()
+	gotObjects, err := got.Enumerate("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantObjects, err := want.Enumerate("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotObjects) != len(wantObjects) {
+		t.Errorf("%d objects, want %d", len(gotObjects), len(wantObjects))
+	}
+	for id, wantObject := range wantObjects {
+		gotObject, ok := gotObjects[id]
+		if !ok {
+			t.Errorf("%s is missing", id)
+			continue
+		}
+		if gotObject.GetDescription() != wantObject.GetDescription() {
+			t.Errorf("%s: type %08x, want %08x", id, uint32(gotObject.GetDescription()), uint32(wantObject.GetDescription()))
+		}
+		gotElements := gotObject.GetElements()
+		if len(gotElements) != len(wantObject.GetElements()) {
+			t.Errorf("%s: %d elements, want %d", id, len(gotElements), len(wantObject.GetElements()))
+		}
+		for key, wantElement := range wantObject.GetElements() {
+			gotElement, ok := gotElements[key]
+			if !ok || gotElement.GetType() != wantElement.GetType() || !bytes.Equal(gotElement.GetRaw(), wantElement.GetRaw()) {
+				t.Errorf("%s %s differs", id, key)
+			}
+		}
+	}
+}
+
+func TestExportStore(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BCD")
+	if err := ExportStore(bcd, file); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := OpenStore(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exported.Close()
+	compareStores(t, exported, bcd)
+
+	// a file backed store is written as it is
+	again := filepath.Join(dir, "BCD.backup")
+	if err = ExportStore(exported, again); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenStore(again, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	compareStores(t, reopened, bcd)
+}
+
+func TestImportStore(t *testing.T) {
+	src := newTestStore(t)
+	defer src.Close()
+
+	firmwareType := model.BcdDescriptionFrom(model.ObjectApplication, model.FirmwareApplication, model.ApplicationBootapp)
+	for _, clean := range []bool{false, true} {
+		bcd := newTestStore(t)
+		loader := mustGetObject(t, bcd, testLoaderId)
+		mustSet(t)(loader.SetBoolean(testBootDebug, true))
+		if _, err := bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+			t.Fatal(err)
+		}
+		firmware, err := bcd.UpsertObject(testSettingsId, firmwareType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustSet(t)(firmware.SetString(model.ElementDescription, "USB"))
+
+		if err = ImportStore(bcd, src, clean); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := mustGetObject(t, bcd, testLoaderId).GetElements()[testBootDebug]; ok {
+			t.Errorf("clean=%t: a stale element survived the import", clean)
+		}
+		if _, err = bcd.GetObject(testOtherId); err == nil {
+			t.Errorf("clean=%t: an object missing from the import survived", clean)
+		}
+		_, err = bcd.GetObject(testSettingsId)
+		if clean == (err == nil) {
+			t.Errorf("clean=%t: firmware entry lookup returned %v", clean, err)
+		}
+		if clean {
+			compareStores(t, bcd, src)
+		}
+		bcd.Close()
+	}
+}
+
+func enumerateJson(t *testing.T, bcd Bcdedit) *model.EnumerateResponse {
+	t.Helper()
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &model.EnumerateResponse{Objects: make(map[string]*model.BcdObject)}
+	for id, object := range objects {
+		response.Objects[id] = object.ToJson()
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &model.EnumerateResponse{}
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestImportJson(t *testing.T) {
+	src := newTestStore(t)
+	defer src.Close()
+	mustSet(t)(mustGetObject(t, src, testLoaderId).SetDevice(model.ElementOsDevice, model.NewBootDevice()))
+	mustSet(t)(mustGetObject(t, src, testLoaderId).SetElement(testBootDebug, RegDword, []byte{1, 0, 0, 0}))
+	response := enumerateJson(t, src)
+
+	bcd, err := CreateMemoryStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bcd.Close()
+	if err = ImportJson(bcd, response, true); err != nil {
+		t.Fatal(err)
+	}
+	compareStores(t, bcd, src)
+
+	// hand-written input may carry the decoded values instead of raw
+	for _, object := range response.Objects {
+		for _, element := range object.Elements {
+			if element.ValueSz != "" || element.ValueMultiSz != nil || element.ValueDword != nil || element.ValueDevice != nil {
+				element.Raw = ""
+			}
+		}
+	}
+	decoded, err := StoreFromJson(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoded.Close()
+	compareStores(t, decoded, src)
+
+	response.Objects[testLoaderId].Elements["nonsense"] = &model.BcdElement{Type: model.RegSz}
+	if _, err = StoreFromJson(response); err == nil {
+		t.Error("invalid element type: expected an error")
+	}
+	response.Objects[testLoaderId].Elements = map[string]*model.BcdElement{"12000004": {Type: "RegUnknown"}}
+	if _, err = StoreFromJson(response); err == nil {
+		t.Error("invalid value type: expected an error")
+	}
+}
@@ -0,0 +1,98 @@
+package go_bcdedit
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+)
+
+var ErrInheritanceCycle = errors.New("inheritance cycle")
+
+// EffectiveElement is an element value together with the object that
+// supplied it.
+type EffectiveElement struct {
+	Key     model.ElementType
+	Element BcdElement
+	Source  string // object id, equals the resolved object for its own elements
+}
+
+// EffectiveElements computes the element set Windows applies to an object
+// by following its Inherit (14000006) list. The object's own elements win;
+// inherited objects are applied in list order, each with its own chain
+// resolved first, so the first object supplying an element wins. Inherit
+// elements of inherited objects are not carried over, and inherited objects
+// that do not exist are skipped like Windows does.
+func EffectiveElements(bcd Bcdedit, objectId string) (map[model.ElementType]*EffectiveElement, error) {
+	effective := make(map[model.ElementType]*EffectiveElement)
+	err := collectEffectiveElements(bcd, objectId, nil, effective)
+	if err != nil {
+		return nil, err
+	}
+	return effective, nil
+}
+
+// SortedEffectiveElements returns the result of EffectiveElements ordered
+// by element type.
+func SortedEffectiveElements(effective map[model.ElementType]*EffectiveElement) []*EffectiveElement {
+	var sorted []*EffectiveElement
+	for _, element := range effective {
+		sorted = append(sorted, element)
+	}
+	slices.SortFunc(sorted, func(a, b *EffectiveElement) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return sorted
+}
+
+func collectEffectiveElements(bcd Bcdedit, objectId string, path []string, effective map[model.ElementType]*EffectiveElement) error {
+	objectId, err := bcd.ResolveObjectId(objectId)
+	if err != nil {
+		return err
+	}
+	for _, visited := range path {
+		if strings.EqualFold(visited, objectId) {
+			return fmt.Errorf("%w: %s -> %s", ErrInheritanceCycle, strings.Join(path, " -> "), objectId)
+		}
+	}
+	path = append(path, objectId)
+
+	object, err := bcd.GetObject(objectId)
+	if errors.Is(err, ErrObjectNotFound) && len(path) > 1 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	elements := object.GetElements()
+	for key, element := range elements {
+		if key == model.ElementInheritedObjects && len(path) > 1 {
+			continue
+		}
+		if _, ok := effective[key]; !ok {
+			effective[key] = &EffectiveElement{
+				Key:     key,
+				Element: element,
+				Source:  object.GetId(),
+			}
+		}
+	}
+
+	inherit, ok := elements[model.ElementInheritedObjects]
+	if !ok {
+		return nil
+	}
+	parents, err := inherit.GetObjectList()
+	if err != nil {
+		return fmt.Errorf("%s: %v", objectId, err)
+	}
+	for _, parentId := range parents {
+		err = collectEffectiveElements(bcd, parentId, path, effective)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
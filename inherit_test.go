@@ -0,0 +1,85 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"github.com/jc-lab/go-bcdedit/model"
+	"testing"
+)
+
+const testSettingsId = "{6efb52bf-1766-41db-a6b3-0ee5eff72bd7}" // {bootloadersettings}
+
+var inheritType = model.BcdDescriptionFrom(model.ObjectInherit, model.InheritableByApplicationObjects, 0)
+
+func TestEffectiveElements(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	settings, err := bcd.UpsertObject(testSettingsId, inheritType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := bcd.UpsertObject(testOtherId, inheritType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := mustGetObject(t, bcd, testLoaderId)
+	mustSet(t)(settings.SetString(model.ElementDescription, "Settings"))
+	mustSet(t)(settings.SetBoolean(testBootDebug, true))
+	mustSet(t)(settings.SetObjectList(model.ElementInheritedObjects, []string{testOtherId}))
+	mustSet(t)(other.SetBoolean(testBootDebug, false))
+	mustSet(t)(other.SetIntegerList(testAllowedSettings, []uint64{1}))
+	// The missing parent is skipped.
+	mustSet(t)(loader.SetObjectList(model.ElementInheritedObjects, []string{"{bootloadersettings}", "{0e0e0e0e-0000-4000-8000-000000000000}"}))
+
+	effective, err := EffectiveElements(bcd, testLoaderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[model.ElementType]string{
+		model.ElementDescription:      testLoaderId,
+		model.ElementInheritedObjects: testLoaderId,
+		testBootDebug:                 testSettingsId,
+		testAllowedSettings:           testOtherId,
+	}
+	if len(effective) != len(want) {
+		t.Errorf("%d effective elements, want %d", len(effective), len(want))
+	}
+	for key, source := range want {
+		if element, ok := effective[key]; !ok || element.Source != source {
+			t.Errorf("%s: got %+v, want it from %s", key, element, source)
+		}
+	}
+	if value, _ := effective[testBootDebug].Element.GetBoolean(); !value {
+		t.Error("the first inherited object does not win")
+	}
+
+	if _, err = EffectiveElements(bcd, testOtherId+"x"); err == nil {
+		t.Error("invalid id: expected an error")
+	}
+	if _, err = EffectiveElements(bcd, "{0e0e0e0e-0000-4000-8000-000000000000}"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("missing object: got %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestEffectiveElementsCycle(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	bcd.(*HiveBcdedit).Aliases = &AliasResolver{Aliases: map[string]string{"{work}": testLoaderId}}
+	settings, err := bcd.UpsertObject(testSettingsId, inheritType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := mustGetObject(t, bcd, testLoaderId)
+	mustSet(t)(loader.SetObjectList(model.ElementInheritedObjects, []string{"{bootloadersettings}"}))
+	mustSet(t)(settings.SetObjectList(model.ElementInheritedObjects, []string{"{work}"}))
+
+	_, err = EffectiveElements(bcd, "{work}")
+	if !errors.Is(err, ErrInheritanceCycle) {
+		t.Fatalf("got %v, want ErrInheritanceCycle", err)
+	}
+	want := "inheritance cycle: " + testLoaderId + " -> " + testSettingsId + " -> " + testLoaderId
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+}
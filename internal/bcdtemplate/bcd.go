@@ -1,6 +1,36 @@
 package bcdtemplate
 
-import _ "embed"
+import (
+	_ "embed"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
+	"unicode/utf16"
+)
 
 //go:embed BCD
 var EMPTY []byte
+
+// Init populates an empty hive with the same layout as EMPTY.
+func Init(h hive.Hive) error {
+	root, err := h.Root()
+	if err != nil {
+		return err
+	}
+	description, err := h.NodeAddChild(root, "Description")
+	if err != nil {
+		return err
+	}
+	var keyName []byte
+	for _, c := range utf16.Encode([]rune("BCD00000001\x00")) {
+		keyName = append(keyName, byte(c), byte(c>>8))
+	}
+	_, err = h.NodeSetValue(description, hive.Value{
+		Type:  hive.RegSz,
+		Key:   "KeyName",
+		Value: keyName,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = h.NodeAddChild(root, "Objects")
+	return err
+}
@@ -0,0 +1,111 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var ErrStoreLocked = errors.New("store is locked by another process")
+
+var ErrLockNotSupported = errors.New("locking not supported on this platform")
+
+type LockMode int
+
+const (
+	// LockAuto takes a shared lock for read-only stores and an exclusive
+	// lock for writable ones.
+	LockAuto LockMode = iota
+	LockShared
+	LockExclusive
+	// LockNone opens the store without locking it.
+	LockNone
+)
+
+// LockOptions controls the advisory lock held on a store while it is open.
+// The lock is taken on a "<store>.lock" file next to the store because
+// commits replace the store file itself. The lock file is left in place on
+// purpose: removing it on close would let another process lock a file that
+// is no longer the one the next opener sees. Shared locks only open an
+// existing lock file, so reading a store never creates one and works in a
+// read-only directory; without a lock file there is no writer to wait for
+// and the store is read unlocked.
+//
+// Locking uses flock(2) and is only available on unix platforms. Elsewhere
+// the default LockAuto opens stores without locking, while an explicit
+// LockShared, LockExclusive or Blocking fails with ErrLockNotSupported.
+type LockOptions struct {
+	Mode LockMode
+	// Blocking waits for the lock instead of failing with ErrStoreLocked.
+	Blocking bool
+	// Timeout limits how long a blocking open waits, zero waits forever.
+	Timeout time.Duration
+}
+
+const lockPollInterval = 50 * time.Millisecond
+
+type storeLock struct {
+	file *os.File
+}
+
+func lockStore(store string, writable bool, options LockOptions) (*storeLock, error) {
+	mode := options.Mode
+	if !flockSupported && mode != LockNone {
+		if mode == LockAuto && !options.Blocking {
+			return nil, nil
+		}
+		return nil, ErrLockNotSupported
+	}
+	if mode == LockAuto {
+		mode = LockShared
+		if writable {
+			mode = LockExclusive
+		}
+	}
+	if mode == LockNone {
+		return nil, nil
+	}
+	if mode == LockShared && writable {
+		return nil, errors.New("a writable store needs an exclusive lock")
+	}
+	exclusive := mode == LockExclusive
+
+	var file *os.File
+	var err error
+	if exclusive {
+		file, err = os.OpenFile(store+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	} else {
+		file, err = os.Open(store + ".lock")
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if options.Blocking && options.Timeout <= 0 {
+		err = flock(file, exclusive, true)
+	} else {
+		deadline := time.Now().Add(options.Timeout)
+		for {
+			err = flock(file, exclusive, false)
+			if !errors.Is(err, ErrStoreLocked) || !options.Blocking || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(lockPollInterval)
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &storeLock{file: file}, nil
+}
+
+func (l *storeLock) Unlock() error {
+	if l == nil {
+		return nil
+	}
+	// closing the descriptor releases the lock
+	return l.file.Close()
+}
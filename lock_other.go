@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package go_bcdedit
+
+import "os"
+
+// flockSupported is false where flock(2) is not available; lockStore does
+// not lock there and rejects options that ask for a lock.
+const flockSupported = false
+
+func flock(file *os.File, exclusive bool, blocking bool) error {
+	return ErrLockNotSupported
+}
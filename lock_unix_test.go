@@ -0,0 +1,85 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package go_bcdedit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreLock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "BCD")
+	bcd, err := CreateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = OpenStore(file, true); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("second exclusive lock: got %v, want ErrStoreLocked", err)
+	}
+	if _, err = OpenStore(file, false); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("shared lock while written: got %v, want ErrStoreLocked", err)
+	}
+	start := time.Now()
+	_, err = OpenStoreWithLock(file, true, LockOptions{Blocking: true, Timeout: 100 * time.Millisecond})
+	if !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("blocking lock: got %v, want ErrStoreLocked", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("blocking lock gave up before its timeout")
+	}
+	unlocked, err := OpenStoreWithLock(file, false, LockOptions{Mode: LockNone})
+	if err != nil {
+		t.Fatalf("LockNone: %v", err)
+	}
+	unlocked.Close()
+	if err = bcd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := OpenStore(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := OpenStore(file, false)
+	if err != nil {
+		t.Fatalf("second shared lock: %v", err)
+	}
+	defer second.Close()
+	if _, err = OpenStore(file, true); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("exclusive lock while read: got %v, want ErrStoreLocked", err)
+	}
+}
+
+func TestStoreLockReadOnlyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BCD")
+	bcd, err := CreateStoreWithLock(file, LockOptions{Mode: LockNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bcd.UpsertObject(testLoaderId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0755)
+
+	bcd, err = OpenStore(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bcd.Close()
+	mustGetObject(t, bcd, testLoaderId)
+	if _, err = os.Stat(file + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("reading the store created a lock file: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+)
+
+type DeviceType uint32
+type BlockIoType uint32
+type PartitionStyle uint32
+
+const (
+	DeviceBlockIo            DeviceType = 0
+	DevicePartition          DeviceType = 2
+	DeviceBoot               DeviceType = 5
+	DeviceQualifiedPartition DeviceType = 6
+	DeviceLocate             DeviceType = 8
+
+	BlockIoHardDisk        BlockIoType = 0
+	BlockIoRemovableDisk   BlockIoType = 1
+	BlockIoCdRom           BlockIoType = 2
+	BlockIoRamdisk         BlockIoType = 3
+	BlockIoFile            BlockIoType = 5
+	BlockIoVirtualHardDisk BlockIoType = 6
+
+	PartitionStyleGpt PartitionStyle = 0
+	PartitionStyleMbr PartitionStyle = 1
+	PartitionStyleRaw PartitionStyle = 2
+)
+
+func (t DeviceType) String() string {
+	switch t {
+	case DeviceBlockIo:
+		return "blockio"
+	case DevicePartition:
+		return "partition"
+	case DeviceBoot:
+		return "boot"
+	case DeviceQualifiedPartition:
+		return "qualifiedpartition"
+	case DeviceLocate:
+		return "locate"
+	default:
+		return ""
+	}
+}
+
+func (t BlockIoType) String() string {
+	switch t {
+	case BlockIoHardDisk:
+		return "disk"
+	case BlockIoRemovableDisk:
+		return "removable"
+	case BlockIoCdRom:
+		return "cdrom"
+	case BlockIoRamdisk:
+		return "ramdisk"
+	case BlockIoFile:
+		return "file"
+	case BlockIoVirtualHardDisk:
+		return "vhd"
+	default:
+		return ""
+	}
+}
+
+// Device is a decoded Device-format element.
+type Device struct {
+	OptionsId string `json:"optionsId,omitempty"` // e.g. ramdisk options object
+	DeviceDescriptor
+}
+
+type DeviceDescriptor struct {
+	Type      DeviceType       `json:"type"`
+	Flags     uint32           `json:"flags,omitempty"`
+	Partition *PartitionDevice `json:"partition,omitempty"`
+	BlockIo   *BlockIoDevice   `json:"blockIo,omitempty"`
+	Locate    *LocateDevice    `json:"locate,omitempty"`
+	Raw       []byte           `json:"raw,omitempty"` // data of unknown device types
+}
+
+type PartitionDevice struct {
+	Number         uint32        `json:"number,omitempty"` // legacy partition devices only
+	MbrOffset      uint64        `json:"mbrOffset,omitempty"`
+	GptPartitionId *Guid         `json:"gptPartitionId,omitempty"`
+	Disk           BlockIoDevice `json:"disk"`
+}
+
+type BlockIoDevice struct {
+	Type BlockIoType `json:"type"`
+	Disk *HardDisk   `json:"disk,omitempty"`
+	File *FilePath   `json:"file,omitempty"` // ramdisk, file and vhd devices
+}
+
+type HardDisk struct {
+	PartitionStyle PartitionStyle `json:"partitionStyle"`
+	MbrSignature   uint32         `json:"mbrSignature,omitempty"`
+	GptDiskId      *Guid          `json:"gptDiskId,omitempty"`
+	DiskNumber     uint32         `json:"diskNumber,omitempty"`
+}
+
+type FilePath struct {
+	Parent DeviceDescriptor `json:"parent"`
+	Path   string           `json:"path"`
+}
+
+type LocateDevice struct {
+	Type         uint32 `json:"type"`
+	ElementType  uint32 `json:"elementType,omitempty"`
+	ParentOffset uint32 `json:"parentOffset,omitempty"`
+	Path         string `json:"path,omitempty"`
+}
+
+func NewBootDevice() *Device {
+	return &Device{DeviceDescriptor: DeviceDescriptor{Type: DeviceBoot}}
+}
+
+func NewGptPartitionDevice(diskId Guid, partitionId Guid) *Device {
+	return &Device{DeviceDescriptor: DeviceDescriptor{
+		Type: DeviceQualifiedPartition,
+		Partition: &PartitionDevice{
+			GptPartitionId: &partitionId,
+			Disk: BlockIoDevice{
+				Type: BlockIoHardDisk,
+				Disk: &HardDisk{PartitionStyle: PartitionStyleGpt, GptDiskId: &diskId},
+			},
+		},
+	}}
+}
+
+// NewMbrPartitionDevice creates a partition device identified by the disk
+// signature and the partition start offset in bytes.
+func NewMbrPartitionDevice(signature uint32, offset uint64) *Device {
+	return &Device{DeviceDescriptor: DeviceDescriptor{
+		Type: DeviceQualifiedPartition,
+		Partition: &PartitionDevice{
+			MbrOffset: offset,
+			Disk: BlockIoDevice{
+				Type: BlockIoHardDisk,
+				Disk: &HardDisk{PartitionStyle: PartitionStyleMbr, MbrSignature: signature},
+			},
+		},
+	}}
+}
+
+func NewLocateDevice(path string) *Device {
+	return &Device{DeviceDescriptor: DeviceDescriptor{
+		Type:   DeviceLocate,
+		Locate: &LocateDevice{Path: path},
+	}}
+}
+
+// NewRamdiskDevice creates a ramdisk loaded from path on parent, with its
+// options (e.g. SdiDevice) kept in the optionsId object.
+func NewRamdiskDevice(parent DeviceDescriptor, path string, optionsId string) *Device {
+	device := newFileBackedDevice(BlockIoRamdisk, parent, path)
+	device.OptionsId = optionsId
+	return device
+}
+
+func NewFileDevice(parent DeviceDescriptor, path string) *Device {
+	return newFileBackedDevice(BlockIoFile, parent, path)
+}
+
+func NewVhdDevice(parent DeviceDescriptor, path string) *Device {
+	return newFileBackedDevice(BlockIoVirtualHardDisk, parent, path)
+}
+
+func newFileBackedDevice(typ BlockIoType, parent DeviceDescriptor, path string) *Device {
+	return &Device{DeviceDescriptor: DeviceDescriptor{
+		Type: DeviceBlockIo,
+		BlockIo: &BlockIoDevice{
+			Type: typ,
+			File: &FilePath{Parent: parent, Path: path},
+		},
+	}}
+}
+
+// String renders the device like bcdedit, e.g.
+// "ramdisk=[boot]\sources\boot.wim,{7619dcc8-fafe-11d9-b411-000476eba25f}".
+func (d *Device) String() string {
+	s := d.DeviceDescriptor.String()
+	if d.OptionsId != "" {
+		s += "," + d.OptionsId
+	}
+	return s
+}
+
+func (d *DeviceDescriptor) String() string {
+	switch d.Type {
+	case DeviceBoot:
+		return "boot"
+	case DevicePartition, DeviceQualifiedPartition:
+		if d.Partition == nil {
+			break
+		}
+		if d.Partition.Disk.File != nil {
+			return d.Partition.Disk.String()
+		}
+		return "partition=" + d.Partition.spec()
+	case DeviceBlockIo:
+		if d.BlockIo == nil {
+			break
+		}
+		return d.BlockIo.String()
+	case DeviceLocate:
+		if d.Locate == nil {
+			break
+		}
+		if d.Locate.Path == "" {
+			if d.Locate.ElementType == 0 {
+				return "locate"
+			}
+			return fmt.Sprintf("locate=custom:%08x", d.Locate.ElementType)
+		}
+		return "locate=" + d.Locate.Path
+	}
+	return fmt.Sprintf("unknown=%d", d.Type)
+}
+
+func (b *BlockIoDevice) String() string {
+	name := b.Type.String()
+	if name == "" {
+		name = fmt.Sprintf("blockio%d", b.Type)
+	}
+	if b.File != nil {
+		return name + "=" + b.File.String()
+	}
+	if b.Disk != nil {
+		return name + "=" + b.Disk.spec()
+	}
+	return name
+}
+
+// String renders "[parent]path" where a partition parent is written without
+// its "partition=" prefix, e.g. "[boot]\sources\boot.wim".
+func (f *FilePath) String() string {
+	parent := f.Parent.String()
+	if f.Parent.Partition != nil && f.Parent.Partition.Disk.File == nil {
+		parent = f.Parent.Partition.spec()
+	}
+	return "[" + parent + "]" + f.Path
+}
+
+func (p *PartitionDevice) spec() string {
+	disk := p.Disk.Disk
+	if disk == nil {
+		return fmt.Sprintf("blockio%d", p.Disk.Type)
+	}
+	switch disk.PartitionStyle {
+	case PartitionStyleGpt:
+		if p.GptPartitionId != nil {
+			return disk.spec() + ":" + p.GptPartitionId.String()
+		}
+	case PartitionStyleMbr:
+		if p.Number != 0 {
+			return fmt.Sprintf("%s:#%d", disk.spec(), p.Number)
+		}
+		return fmt.Sprintf("%s:%d", disk.spec(), p.MbrOffset)
+	}
+	return fmt.Sprintf("%s:#%d", disk.spec(), p.Number)
+}
+
+func (h *HardDisk) spec() string {
+	switch h.PartitionStyle {
+	case PartitionStyleGpt:
+		if h.GptDiskId != nil {
+			return "gpt:" + h.GptDiskId.String()
+		}
+		return "gpt:"
+	case PartitionStyleMbr:
+		return fmt.Sprintf("mbr:%08x", h.MbrSignature)
+	default:
+		return fmt.Sprintf("raw:%d", h.DiskNumber)
+	}
+}
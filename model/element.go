@@ -0,0 +1,122 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ElementType is a BCD element type such as 0x11000001 (Device): class in
+// bits 28-31, format in bits 24-27 and subtype in bits 0-23.
+type ElementType uint32
+type ElementClass uint32
+type ElementFormat uint32
+
+const (
+	ElementClassLibrary     ElementClass = 0x10000000
+	ElementClassApplication ElementClass = 0x20000000
+	ElementClassDevice      ElementClass = 0x30000000
+	ElementClassTemplate    ElementClass = 0x40000000
+
+	ElementFormatDevice      ElementFormat = 0x01000000
+	ElementFormatString      ElementFormat = 0x02000000
+	ElementFormatObject      ElementFormat = 0x03000000
+	ElementFormatObjectList  ElementFormat = 0x04000000
+	ElementFormatInteger     ElementFormat = 0x05000000
+	ElementFormatBoolean     ElementFormat = 0x06000000
+	ElementFormatIntegerList ElementFormat = 0x07000000
+)
+
+// ParseElementType parses an element key such as "11000001" or "0x11000001".
+func ParseElementType(s string) (ElementType, error) {
+	trimmed := strings.TrimPrefix(strings.ToLower(s), "0x")
+	if len(trimmed) != 8 {
+		return 0, fmt.Errorf("invalid element type: %s", s)
+	}
+	n, err := strconv.ParseUint(trimmed, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid element type: %s", s)
+	}
+	return ElementType(n), nil
+}
+
+func (t ElementType) Class() ElementClass {
+	return ElementClass(t & 0xf0000000)
+}
+
+func (t ElementType) Format() ElementFormat {
+	return ElementFormat(t & 0x0f000000)
+}
+
+func (t ElementType) SubType() uint32 {
+	return uint32(t & 0x00ffffff)
+}
+
+// String returns the registry key name of the element, e.g. "11000001".
+func (t ElementType) String() string {
+	return fmt.Sprintf("%08x", uint32(t))
+}
+
+func (c ElementClass) String() string {
+	switch c {
+	case ElementClassLibrary:
+		return "library"
+	case ElementClassApplication:
+		return "application"
+	case ElementClassDevice:
+		return "device"
+	case ElementClassTemplate:
+		return "template"
+	default:
+		return ""
+	}
+}
+
+func (f ElementFormat) String() string {
+	switch f {
+	case ElementFormatDevice:
+		return "device"
+	case ElementFormatString:
+		return "string"
+	case ElementFormatObject:
+		return "object"
+	case ElementFormatObjectList:
+		return "objectlist"
+	case ElementFormatInteger:
+		return "integer"
+	case ElementFormatBoolean:
+		return "boolean"
+	case ElementFormatIntegerList:
+		return "integerlist"
+	default:
+		return ""
+	}
+}
+
+// ValueType returns the registry value type used to store elements of the format.
+func (f ElementFormat) ValueType() ValueType {
+	switch f {
+	case ElementFormatString, ElementFormatObject:
+		return RegSz
+	case ElementFormatObjectList:
+		return RegMultiSz
+	case ElementFormatDevice, ElementFormatInteger, ElementFormatBoolean, ElementFormatIntegerList:
+		return RegBinary
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestParseElementType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ElementType
+	}{
+		{"11000001", ElementApplicationDevice},
+		{"0x12000004", ElementDescription},
+		{"0X25000004", ElementTimeout},
+		{"2500012B", 0x2500012b},
+	}
+	for _, test := range tests {
+		got, err := ParseElementType(test.input)
+		if err != nil || got != test.want {
+			t.Errorf("%s: got %s, %v, want %s", test.input, got, err, test.want)
+		}
+	}
+	for _, input := range []string{"", "1200004", "120000040", "0x1200000g", "description"} {
+		if _, err := ParseElementType(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestElementTypeFields(t *testing.T) {
+	key := ElementType(0x250000c2)
+	if key.Class() != ElementClassApplication || key.Format() != ElementFormatInteger || key.SubType() != 0xc2 {
+		t.Errorf("%s: class %s, format %s, subtype %x", key, key.Class(), key.Format(), key.SubType())
+	}
+	if key.String() != "250000c2" {
+		t.Errorf("String() = %s", key.String())
+	}
+	if ElementDescription.Format().ValueType() != RegSz || ElementDisplayOrder.Format().ValueType() != RegMultiSz {
+		t.Error("unexpected value types")
+	}
+}
+
+func TestElementMeta(t *testing.T) {
+	for application, elementTypes := range BcdApplicationElementTypes {
+		for key, meta := range elementTypes {
+			elementType, err := ParseElementType(key)
+			if err != nil {
+				t.Errorf("%s: %v", application, err)
+				continue
+			}
+			if key != elementType.String() {
+				t.Errorf("%s: key %s is not in the form of ElementType.String()", application, key)
+			}
+			if meta.Format != elementType.Format().String() {
+				t.Errorf("%s: %s has format %q", application, key, meta.Format)
+			}
+		}
+	}
+	if meta := GenericElementTypes[ElementDescription.String()]; meta == nil || meta.Format != "string" {
+		t.Errorf("description: %+v", meta)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Guid is a GUID in its Windows binary layout (little endian Data1..Data3).
+type Guid [16]byte
+
+func GuidFromBytes(b []byte) Guid {
+	var g Guid
+	copy(g[:], b)
+	return g
+}
+
+// ParseGuid parses "{xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}" with or without braces.
+func ParseGuid(s string) (Guid, error) {
+	var g Guid
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if len(trimmed) != 36 || trimmed[8] != '-' || trimmed[13] != '-' || trimmed[18] != '-' || trimmed[23] != '-' {
+		return g, fmt.Errorf("invalid guid: %s", s)
+	}
+	raw, err := hex.DecodeString(strings.ReplaceAll(trimmed, "-", ""))
+	if err != nil {
+		return g, fmt.Errorf("invalid guid: %s", s)
+	}
+	binary.LittleEndian.PutUint32(g[0:], binary.BigEndian.Uint32(raw[0:]))
+	binary.LittleEndian.PutUint16(g[4:], binary.BigEndian.Uint16(raw[4:]))
+	binary.LittleEndian.PutUint16(g[6:], binary.BigEndian.Uint16(raw[6:]))
+	copy(g[8:], raw[8:])
+	return g, nil
+}
+
+func (g Guid) IsZero() bool {
+	return g == Guid{}
+}
+
+func (g Guid) String() string {
+	return fmt.Sprintf("{%08x-%04x-%04x-%x-%x}",
+		binary.LittleEndian.Uint32(g[0:]),
+		binary.LittleEndian.Uint16(g[4:]),
+		binary.LittleEndian.Uint16(g[6:]),
+		g[8:10],
+		g[10:16],
+	)
+}
+
+func (g Guid) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+func (g *Guid) UnmarshalText(text []byte) error {
+	parsed, err := ParseGuid(string(text))
+	if err != nil {
+		return err
+	}
+	*g = parsed
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// ObjectId is a BCD object identifier. Objects are stored under their GUID
+// in braces, and the canonical form is lowercase, e.g.
+// "{9dea862c-5cdd-4e70-acc1-f32b344d4795}".
+type ObjectId Guid
+
+// ParseObjectId parses a GUID with or without braces.
+func ParseObjectId(s string) (ObjectId, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") != strings.HasSuffix(s, "}") {
+		return ObjectId{}, fmt.Errorf("invalid object id: %s", s)
+	}
+	g, err := ParseGuid(s)
+	if err != nil {
+		return ObjectId{}, fmt.Errorf("invalid object id: %s", s)
+	}
+	return ObjectId(g), nil
+}
+
+// NewObjectId generates a random (version 4) GUID.
+func NewObjectId() (ObjectId, error) {
+	var id ObjectId
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, err
+	}
+	id[7] = id[7]&0x0f | 0x40 // high nibble of Data3, stored little endian
+	id[8] = id[8]&0x3f | 0x80
+	return id, nil
+}
+
+// CanonicalObjectId returns the canonical form of an object id string.
+func CanonicalObjectId(s string) (string, error) {
+	id, err := ParseObjectId(s)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (id ObjectId) Guid() Guid {
+	return Guid(id)
+}
+
+func (id ObjectId) String() string {
+	return Guid(id).String()
+}
+
+func (id ObjectId) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *ObjectId) UnmarshalText(text []byte) error {
+	parsed, err := ParseObjectId(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseObjectId(t *testing.T) {
+	const canonical = "{9dea862c-5cdd-4e70-acc1-f32b344d4795}"
+	for _, input := range []string{
+		canonical,
+		"9dea862c-5cdd-4e70-acc1-f32b344d4795",
+		"{9DEA862C-5CDD-4E70-ACC1-F32B344D4795}",
+		" {9dea862c-5cdd-4e70-acc1-f32b344d4795} ",
+	} {
+		got, err := CanonicalObjectId(input)
+		if err != nil || got != canonical {
+			t.Errorf("%q: got %s, %v", input, got, err)
+		}
+	}
+	for _, input := range []string{
+		"",
+		"{bootmgr}",
+		"{9dea862c-5cdd-4e70-acc1-f32b344d4795",
+		"9dea862c-5cdd-4e70-acc1-f32b344d4795}",
+		"{9dea862c5cdd-4e70-acc1-f32b344d4795-}",
+		"{9dea862c-5cdd-4e70-acc1-f32b344d479g}",
+	} {
+		if _, err := ParseObjectId(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestNewObjectId(t *testing.T) {
+	seen := make(map[ObjectId]bool)
+	for i := 0; i < 100; i++ {
+		id, err := NewObjectId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := id.String()
+		if s[15] != '4' || s[20] != '8' && s[20] != '9' && s[20] != 'a' && s[20] != 'b' {
+			t.Errorf("%s is not a version 4 GUID", s)
+		}
+		if seen[id] {
+			t.Errorf("%s generated twice", s)
+		}
+		seen[id] = true
+		parsed, err := ParseObjectId(s)
+		if err != nil || parsed != id {
+			t.Errorf("%s: parsed as %s, %v", s, parsed, err)
+		}
+	}
+}
+
+func TestObjectIdText(t *testing.T) {
+	var decoded struct {
+		Id ObjectId `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(`{"id": "{A5A30FA2-3D06-4E9F-B5F4-A01DF9D1FCBA}"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"id":"{a5a30fa2-3d06-4e9f-b5f4-a01df9d1fcba}"}` {
+		t.Errorf("encoded as %s", encoded)
+	}
+	if err = json.Unmarshal([]byte(`{"id": "fwbootmgr"}`), &decoded); err == nil {
+		t.Error("invalid id: expected an error")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 JC-Lab
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "strings"
+
+// BcdOption maps a bcdedit option name (e.g. "nx") to its element type.
+type BcdOption struct {
+	Name         string
+	Type         ElementType
+	Values       []BcdOptionValue  // named values of integer options, e.g. OptIn
+	Applications []ApplicationType // nil if the option applies to any object
+}
+
+type BcdOptionValue struct {
+	Name  string
+	Value uint64
+}
+
+var (
+	debugTypeValues = []BcdOptionValue{
+		{"Serial", 0}, {"1394", 1}, {"USB", 2}, {"Net", 3}, {"Local", 4},
+	}
+	bootMgrApplications  = []ApplicationType{ApplicationFwbootmgr, ApplicationBootmgr}
+	osLoaderApplications = []ApplicationType{ApplicationOsloader}
+	resumeApplications   = []ApplicationType{ApplicationResume}
+)
+
+// BcdOptions https://learn.microsoft.com/en-us/windows-hardware/drivers/devtest/bcdedit--set
+var BcdOptions = []*BcdOption{
+	// library
+	{Name: "device", Type: 0x11000001},
+	{Name: "path", Type: 0x12000002},
+	{Name: "description", Type: 0x12000004},
+	{Name: "locale", Type: 0x12000005},
+	{Name: "inherit", Type: 0x14000006},
+	{Name: "truncatememory", Type: 0x15000007},
+	{Name: "recoverysequence", Type: 0x14000008},
+	{Name: "recoveryenabled", Type: 0x16000009},
+	{Name: "badmemorylist", Type: 0x1700000A},
+	{Name: "badmemoryaccess", Type: 0x1600000B},
+	{Name: "firstmegabytepolicy", Type: 0x1500000C, Values: []BcdOptionValue{
+		{"UseNone", 0}, {"UseAll", 1}, {"UsePrivate", 2},
+	}},
+	{Name: "bootdebug", Type: 0x16000010},
+	{Name: "debugtype", Type: 0x15000011, Values: debugTypeValues},
+	{Name: "debugport", Type: 0x15000013},
+	{Name: "baudrate", Type: 0x15000014},
+	{Name: "channel", Type: 0x15000015},
+	{Name: "targetname", Type: 0x12000016},
+	{Name: "noumex", Type: 0x16000017},
+	{Name: "debugstart", Type: 0x15000018, Values: []BcdOptionValue{
+		{"Active", 0}, {"AutoEnable", 1}, {"Disable", 2},
+	}},
+	{Name: "busparams", Type: 0x12000019},
+	{Name: "hostip", Type: 0x1500001A},
+	{Name: "port", Type: 0x1500001B},
+	{Name: "dhcp", Type: 0x1600001C},
+	{Name: "key", Type: 0x1200001D},
+	{Name: "bootems", Type: 0x16000020},
+	{Name: "emsport", Type: 0x15000022},
+	{Name: "emsbaudrate", Type: 0x15000023},
+	{Name: "loadoptions", Type: 0x12000030},
+	{Name: "advancedoptions", Type: 0x16000040},
+	{Name: "optionsedit", Type: 0x16000041},
+	{Name: "graphicsmodedisabled", Type: 0x16000046},
+	{Name: "configaccesspolicy", Type: 0x15000047, Values: []BcdOptionValue{
+		{"Default", 0}, {"DisallowMmConfig", 1},
+	}},
+	{Name: "nointegritychecks", Type: 0x16000048},
+	{Name: "testsigning", Type: 0x16000049},
+
+	// bootmgr
+	{Name: "displayorder", Type: 0x24000001, Applications: bootMgrApplications},
+	{Name: "bootsequence", Type: 0x24000002, Applications: bootMgrApplications},
+	{Name: "default", Type: 0x23000003, Applications: bootMgrApplications},
+	{Name: "timeout", Type: 0x25000004, Applications: bootMgrApplications},
+	{Name: "resume", Type: 0x26000005, Applications: bootMgrApplications},
+	{Name: "resumeobject", Type: 0x23000006, Applications: bootMgrApplications},
+	{Name: "toolsdisplayorder", Type: 0x24000010, Applications: bootMgrApplications},
+	{Name: "displaybootmenu", Type: 0x26000020, Applications: bootMgrApplications},
+	{Name: "noerrordisplay", Type: 0x26000021, Applications: bootMgrApplications},
+	{Name: "bcddevice", Type: 0x21000022, Applications: bootMgrApplications},
+	{Name: "bcdfilepath", Type: 0x22000023, Applications: bootMgrApplications},
+	{Name: "customactions", Type: 0x27000030, Applications: bootMgrApplications},
+	{Name: "persistbootsequence", Type: 0x26000031, Applications: bootMgrApplications},
+
+	// osloader
+	{Name: "osdevice", Type: 0x21000001, Applications: osLoaderApplications},
+	{Name: "systemroot", Type: 0x22000002, Applications: osLoaderApplications},
+	{Name: "resumeobject", Type: 0x23000003, Applications: osLoaderApplications},
+	{Name: "detecthal", Type: 0x26000010, Applications: osLoaderApplications},
+	{Name: "kernel", Type: 0x22000011, Applications: osLoaderApplications},
+	{Name: "hal", Type: 0x22000012, Applications: osLoaderApplications},
+	{Name: "dbgtransport", Type: 0x22000013, Applications: osLoaderApplications},
+	{Name: "nx", Type: 0x25000020, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"OptIn", 0}, {"OptOut", 1}, {"AlwaysOff", 2}, {"AlwaysOn", 3},
+	}},
+	{Name: "pae", Type: 0x25000021, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"ForceEnable", 1}, {"ForceDisable", 2},
+	}},
+	{Name: "winpe", Type: 0x26000022, Applications: osLoaderApplications},
+	{Name: "nocrashautoreboot", Type: 0x26000024, Applications: osLoaderApplications},
+	{Name: "lastknowngood", Type: 0x26000025, Applications: osLoaderApplications},
+	{Name: "nolowmem", Type: 0x26000030, Applications: osLoaderApplications},
+	{Name: "removememory", Type: 0x25000031, Applications: osLoaderApplications},
+	{Name: "increaseuserva", Type: 0x25000032, Applications: osLoaderApplications},
+	{Name: "vga", Type: 0x26000040, Applications: osLoaderApplications},
+	{Name: "quietboot", Type: 0x26000041, Applications: osLoaderApplications},
+	{Name: "novesa", Type: 0x26000042, Applications: osLoaderApplications},
+	{Name: "novga", Type: 0x26000043, Applications: osLoaderApplications},
+	{Name: "clustermodeaddressing", Type: 0x25000050, Applications: osLoaderApplications},
+	{Name: "usephysicaldestination", Type: 0x26000051, Applications: osLoaderApplications},
+	{Name: "restrictapiccluster", Type: 0x25000052, Applications: osLoaderApplications},
+	{Name: "uselegacyapicmode", Type: 0x26000054, Applications: osLoaderApplications},
+	{Name: "x2apicpolicy", Type: 0x25000055, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"Disable", 1}, {"Enable", 2},
+	}},
+	{Name: "onecpu", Type: 0x26000060, Applications: osLoaderApplications},
+	{Name: "numproc", Type: 0x25000061, Applications: osLoaderApplications},
+	{Name: "maxproc", Type: 0x26000062, Applications: osLoaderApplications},
+	{Name: "configflags", Type: 0x25000063, Applications: osLoaderApplications},
+	{Name: "maxgroup", Type: 0x26000064, Applications: osLoaderApplications},
+	{Name: "groupaware", Type: 0x26000065, Applications: osLoaderApplications},
+	{Name: "groupsize", Type: 0x25000066, Applications: osLoaderApplications},
+	{Name: "usefirmwarepcisettings", Type: 0x26000070, Applications: osLoaderApplications},
+	{Name: "msi", Type: 0x25000071, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"ForceDisable", 1},
+	}},
+	{Name: "safeboot", Type: 0x25000080, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Minimal", 0}, {"Network", 1}, {"DsRepair", 2},
+	}},
+	{Name: "safebootalternateshell", Type: 0x26000081, Applications: osLoaderApplications},
+	{Name: "bootlog", Type: 0x26000090, Applications: osLoaderApplications},
+	{Name: "sos", Type: 0x26000091, Applications: osLoaderApplications},
+	{Name: "debug", Type: 0x260000A0, Applications: osLoaderApplications},
+	{Name: "halbreakpoint", Type: 0x260000A1, Applications: osLoaderApplications},
+	{Name: "useplatformclock", Type: 0x260000A2, Applications: osLoaderApplications},
+	{Name: "forcelegacyplatform", Type: 0x260000A3, Applications: osLoaderApplications},
+	{Name: "useplatformtick", Type: 0x260000A4, Applications: osLoaderApplications},
+	{Name: "disabledynamictick", Type: 0x260000A5, Applications: osLoaderApplications},
+	{Name: "tscsyncpolicy", Type: 0x250000A6, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"Legacy", 1}, {"Enhanced", 2},
+	}},
+	{Name: "ems", Type: 0x260000B0, Applications: osLoaderApplications},
+	{Name: "driverloadfailurepolicy", Type: 0x250000C1, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Fatal", 0}, {"UseErrorControl", 1},
+	}},
+	{Name: "bootmenupolicy", Type: 0x250000C2, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Legacy", 0}, {"Standard", 1},
+	}},
+	{Name: "onetimeadvancedoptions", Type: 0x260000C3, Applications: osLoaderApplications},
+	{Name: "bootstatuspolicy", Type: 0x250000E0, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"DisplayAllFailures", 0}, {"IgnoreAllFailures", 1}, {"IgnoreShutdownFailures", 2},
+		{"IgnoreBootFailures", 3}, {"IgnoreCheckpointFailures", 4}, {"DisplayShutdownFailures", 5},
+		{"DisplayBootFailures", 6}, {"DisplayCheckpointFailures", 7},
+	}},
+	{Name: "disableelamdrivers", Type: 0x260000E1, Applications: osLoaderApplications},
+	{Name: "hypervisorlaunchtype", Type: 0x250000F0, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Off", 0}, {"Auto", 1},
+	}},
+	{Name: "hypervisordebug", Type: 0x260000F2, Applications: osLoaderApplications},
+	{Name: "hypervisordebugtype", Type: 0x250000F3, Applications: osLoaderApplications, Values: debugTypeValues},
+	{Name: "hypervisordebugport", Type: 0x250000F4, Applications: osLoaderApplications},
+	{Name: "hypervisorbaudrate", Type: 0x250000F5, Applications: osLoaderApplications},
+	{Name: "hypervisorchannel", Type: 0x250000F6, Applications: osLoaderApplications},
+	{Name: "bootux", Type: 0x250000F7, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Disabled", 0}, {"Basic", 1}, {"Standard", 2},
+	}},
+	{Name: "hypervisorbusparams", Type: 0x220000F9, Applications: osLoaderApplications},
+	{Name: "hypervisornumproc", Type: 0x250000FA, Applications: osLoaderApplications},
+	{Name: "hypervisorrootprocpernode", Type: 0x250000FB, Applications: osLoaderApplications},
+	{Name: "hypervisoruselargevtlb", Type: 0x260000FC, Applications: osLoaderApplications},
+	{Name: "hypervisorhostip", Type: 0x250000FD, Applications: osLoaderApplications},
+	{Name: "hypervisorhostport", Type: 0x250000FE, Applications: osLoaderApplications},
+	{Name: "tpmbootentropy", Type: 0x25000100, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"ForceDisable", 1}, {"ForceEnable", 2},
+	}},
+	{Name: "hypervisorusekey", Type: 0x22000110, Applications: osLoaderApplications},
+	{Name: "hypervisordhcp", Type: 0x26000114, Applications: osLoaderApplications},
+	{Name: "hypervisoriommupolicy", Type: 0x25000115, Applications: osLoaderApplications, Values: []BcdOptionValue{
+		{"Default", 0}, {"Enable", 1}, {"Disable", 2},
+	}},
+	{Name: "xsavedisable", Type: 0x2500012B, Applications: osLoaderApplications},
+
+	// resume
+	{Name: "filedevice", Type: 0x21000001, Applications: resumeApplications},
+	{Name: "filepath", Type: 0x22000002, Applications: resumeApplications},
+
+	// device
+	{Name: "ramdiskimageoffset", Type: 0x35000001},
+	{Name: "ramdisktftpclientport", Type: 0x35000002},
+	{Name: "ramdisksdidevice", Type: 0x31000003},
+	{Name: "ramdisksdipath", Type: 0x32000004},
+	{Name: "ramdiskimagelength", Type: 0x35000005},
+	{Name: "exportascd", Type: 0x36000006},
+}
+
+// FindOption looks up a bcdedit option name for an object of the given
+// application type. Names are case-insensitive.
+func FindOption(name string, application ApplicationType) *BcdOption {
+	for _, option := range BcdOptions {
+		if strings.EqualFold(option.Name, name) && option.appliesTo(application) {
+			return option
+		}
+	}
+	return nil
+}
+
+// FindOptionByType returns the bcdedit option of an element type, if any.
+func FindOptionByType(key ElementType, application ApplicationType) *BcdOption {
+	for _, option := range BcdOptions {
+		if option.Type == key && option.appliesTo(application) {
+			return option
+		}
+	}
+	return nil
+}
+
+func (o *BcdOption) appliesTo(application ApplicationType) bool {
+	if o.Applications == nil {
+		return true
+	}
+	for _, a := range o.Applications {
+		if a == application {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseValue returns the value of a named option value such as "OptIn".
+func (o *BcdOption) ParseValue(name string) (uint64, bool) {
+	for _, v := range o.Values {
+		if strings.EqualFold(v.Name, name) {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// ValueName returns the name of an option value, or "" if it has none.
+func (o *BcdOption) ValueName(value uint64) string {
+	for _, v := range o.Values {
+		if v.Value == value {
+			return v.Name
+		}
+	}
+	return ""
+}
@@ -20,6 +20,8 @@ type BcdElement struct {
 	ValueSz      string    `json:"valueSz,omitempty"`
 	ValueMultiSz []string  `json:"valueMultiSz,omitempty"`
 	ValueDword   *uint32   `json:"valueDword,omitempty"`
+	ValueDevice  *Device   `json:"valueDevice,omitempty"`
+	Source       string    `json:"source,omitempty"` // supplying object of effective elements
 }
 
 type BcdObject struct {
@@ -30,3 +32,107 @@ type BcdObject struct {
 type EnumerateResponse struct {
 	Objects map[string]*BcdObject `json:"objects"` // e.g. key="{b2721d73-1db4-4c62-bf78-c548a880142d}"
 }
+
+type ValidationIssueKind string
+
+const (
+	IssueDanglingReference ValidationIssueKind = "dangling-reference"
+	IssueInheritanceCycle  ValidationIssueKind = "inheritance-cycle"
+	IssueTypeMismatch      ValidationIssueKind = "type-mismatch"
+	IssueClassMismatch     ValidationIssueKind = "class-mismatch"
+	IssueMissingElement    ValidationIssueKind = "missing-element"
+	IssueUnknownElement    ValidationIssueKind = "unknown-element"
+)
+
+type ValidationIssue struct {
+	Kind     ValidationIssueKind `json:"kind"`
+	ObjectId string              `json:"objectId"`
+	Element  string              `json:"element,omitempty"` // e.g. "24000001"
+	Message  string              `json:"message"`
+}
+
+type ValidateResponse struct {
+	Issues []*ValidationIssue `json:"issues"`
+}
+
+// ApplySpec is a desired store state: object ids or aliases mapped to
+// element values keyed by option or element name (e.g. "timeout"). A null
+// value removes the element, and the reserved key "type" gives the object
+// type (e.g. "osloader" or 0x10200003). Missing objects are created with it
+// and existing objects of another type are changed to it.
+type ApplySpec map[string]map[string]interface{}
+
+type ApplyActionKind string
+
+const (
+	ActionCreateObject  ApplyActionKind = "create-object"
+	ActionSetType       ApplyActionKind = "set-type"
+	ActionSetElement    ApplyActionKind = "set-element"
+	ActionDeleteElement ApplyActionKind = "delete-element"
+)
+
+type ApplyAction struct {
+	Kind     ApplyActionKind `json:"kind"`
+	ObjectId string          `json:"objectId"`
+	Element  string          `json:"element,omitempty"` // e.g. "25000004"
+	Name     string          `json:"name,omitempty"`    // e.g. "timeout"
+}
+
+type ApplyResponse struct {
+	Changed bool           `json:"changed"`
+	Actions []*ApplyAction `json:"actions"`
+}
+
+type PatchOp string
+
+const (
+	PatchAddObject      PatchOp = "add-object"
+	PatchRemoveObject   PatchOp = "remove-object"
+	PatchSetType        PatchOp = "set-type"
+	PatchAddElement     PatchOp = "add-element"
+	PatchRemoveElement  PatchOp = "remove-element"
+	PatchReplaceElement PatchOp = "replace-element"
+)
+
+// PatchOperation is one difference between two stores. Old values are
+// checked when the operation is applied, so a patch only applies to the
+// store it was made from.
+type PatchOperation struct {
+	Op             PatchOp         `json:"op"`
+	ObjectId       string          `json:"objectId"`
+	Element        string          `json:"element,omitempty"` // e.g. "25000004"
+	Name           string          `json:"name,omitempty"`    // e.g. "timeout"
+	OldDescription *BcdDescription `json:"oldDescription,omitempty"`
+	Description    *BcdDescription `json:"description,omitempty"`
+	Old            *BcdElement     `json:"old,omitempty"`
+	New            *BcdElement     `json:"new,omitempty"`
+	OldValue       string          `json:"oldValue,omitempty"` // decoded, e.g. "Yes"
+	NewValue       string          `json:"newValue,omitempty"`
+}
+
+type Patch struct {
+	Operations []*PatchOperation `json:"operations"`
+}
+
+// Reference is an element of one object pointing at another object, e.g.
+// the displayorder of {bootmgr} or the ramdisk options of a device.
+type Reference struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Element  string `json:"element"`        // e.g. "24000001"
+	Name     string `json:"name,omitempty"` // e.g. "displayorder"
+	Dangling bool   `json:"dangling,omitempty"`
+}
+
+type ReferencesResponse struct {
+	ObjectId string       `json:"objectId"`
+	Inbound  []*Reference `json:"inbound"`
+	Outbound []*Reference `json:"outbound"`
+}
+
+// AliasConfig is the user alias file, mapping names like "{work}" to object
+// ids or other aliases, and optionally naming the {current} entry.
+type AliasConfig struct {
+	Aliases map[string]string `json:"aliases"`
+	Current string            `json:"current,omitempty"`
+}
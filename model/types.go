@@ -89,425 +89,345 @@ const (
 	RegQword                    ValueType = "RegQword"
 )
 
+// BcdElementMeta describes a known element. The element type maps below are
+// keyed by the registry key name of the element, as returned by
+// ElementType.String().
 type BcdElementMeta struct {
-	Name   string
+	Name string
+	// Deprecated: Format is the name of the element format, filled in from
+	// ElementType.Format. Use ElementType.Format instead.
 	Format string
 }
 
 var GenericElementTypes = map[string]*BcdElementMeta{
 	"11000001": {
-		Name:   "Device",
-		Format: "Device",
+		Name: "Device",
 	},
 	"12000002": {
-		Name:   "Path",
-		Format: "string",
+		Name: "Path",
 	},
 	"12000004": {
-		Name:   "Description",
-		Format: "string",
+		Name: "Description",
 	},
 	"12000005": {
-		Name:   "Locale",
-		Format: "string",
+		Name: "Locale",
 	},
 	"14000006": {
-		Name:   "Inherit",
-		Format: "GUID list",
+		Name: "Inherit",
 	},
 	"14000008": {
-		Name:   "RecoverySequence",
-		Format: "GUID list",
+		Name: "RecoverySequence",
 	},
 	"16000009": {
-		Name:   "RecoveryEnabled",
-		Format: "boolean",
+		Name: "RecoveryEnabled",
 	},
 }
 
 // BcdBootMgrElementTypes http://msdn.microsoft.com/en-us/library/windows/desktop/aa362641(v=vs.85).aspx
 var BcdBootMgrElementTypes = map[string]*BcdElementMeta{
 	"24000001": {
-		Name:   "DisplayOrder",
-		Format: "ObjectList",
+		Name: "DisplayOrder",
 	},
 	"24000002": {
-		Name:   "BootSequence",
-		Format: "ObjectList",
+		Name: "BootSequence",
 	},
 	"23000003": {
-		Name:   "DefaultObject",
-		Format: "Object",
+		Name: "DefaultObject",
 	},
 	"25000004": {
-		Name:   "Timeout",
-		Format: "Integer",
+		Name: "Timeout",
 	},
 	"26000005": {
-		Name:   "AttemptResume",
-		Format: "Boolean",
+		Name: "AttemptResume",
 	},
 	"23000006": {
-		Name:   "ResumeObject",
-		Format: "Object",
+		Name: "ResumeObject",
 	},
 	"24000010": {
-		Name:   "ToolsDisplayOrder",
-		Format: "ObjectList",
+		Name: "ToolsDisplayOrder",
 	},
 	"26000020": {
-		Name:   "DisplayBootMenu",
-		Format: "Boolean",
+		Name: "DisplayBootMenu",
 	},
 	"26000021": {
-		Name:   "NoErrorDisplay",
-		Format: "Boolean",
+		Name: "NoErrorDisplay",
 	},
 	"21000022": {
-		Name:   "BcdDevice",
-		Format: "Device",
+		Name: "BcdDevice",
 	},
 	"22000023": {
-		Name:   "BcdFilePath",
-		Format: "String",
+		Name: "BcdFilePath",
 	},
 	"26000028": {
-		Name:   "ProcessCustomActionsFirst",
-		Format: "Boolean",
+		Name: "ProcessCustomActionsFirst",
 	},
 	"27000030": {
-		Name:   "CustomActionsList",
-		Format: "IntegerList",
+		Name: "CustomActionsList",
 	},
 	"26000031": {
-		Name:   "PersistBootSequence",
-		Format: "Boolean",
+		Name: "PersistBootSequence",
 	},
 }
 
 // BcdDeviceElementTypes https://learn.microsoft.com/ko-kr/previous-versions/windows/desktop/bcd/bcddeviceobjectelementtypes?redirectedfrom=MSDN
 var BcdDeviceElementTypes = map[string]*BcdElementMeta{
 	"35000001": {
-		Name:   "RamdiskImageOffset",
-		Format: "Integer",
+		Name: "RamdiskImageOffset",
 	},
 	"35000002": {
-		Name:   "TftpClientPort",
-		Format: "Integer",
+		Name: "TftpClientPort",
 	},
 	"31000003": {
-		Name:   "SdiDevice",
-		Format: "Integer",
+		Name: "SdiDevice",
 	},
 	"32000004": {
-		Name:   "SdiPath",
-		Format: "Integer",
+		Name: "SdiPath",
 	},
 	"35000005": {
-		Name:   "RamdiskImageLength",
-		Format: "Integer",
+		Name: "RamdiskImageLength",
 	},
 	"36000006": {
-		Name:   "RamdiskExportAsCd",
-		Format: "Boolean",
+		Name: "RamdiskExportAsCd",
 	},
 	"36000007": {
-		Name:   "RamdiskTftpBlockSize",
-		Format: "Integer",
+		Name: "RamdiskTftpBlockSize",
 	},
 	"36000008": {
-		Name:   "RamdiskTftpWindowSize",
-		Format: "Integer",
+		Name: "RamdiskTftpWindowSize",
 	},
 	"36000009": {
-		Name:   "RamdiskMulticastEnabled",
-		Format: "Boolean",
+		Name: "RamdiskMulticastEnabled",
 	},
-	"3600000A": {
-		Name:   "RamdiskMulticastTftpFallback",
-		Format: "Boolean",
+	"3600000a": {
+		Name: "RamdiskMulticastTftpFallback",
 	},
-	"3600000B": {
-		Name:   "RamdiskTftpVarWindow",
-		Format: "Boolean",
+	"3600000b": {
+		Name: "RamdiskTftpVarWindow",
 	},
 }
 
 // BcdOsLoaderElementTypes https://learn.microsoft.com/ko-kr/previous-versions/windows/desktop/bcd/bcdosloaderelementtypes?redirectedfrom=MSDN
 var BcdOsLoaderElementTypes = map[string]*BcdElementMeta{
 	"21000001": {
-		Name:   "OSDevice",
-		Format: "Device",
+		Name: "OSDevice",
 	},
 	"22000002": {
-		Name:   "SystemRoot",
-		Format: "String",
+		Name: "SystemRoot",
 	},
 	"23000003": {
-		Name:   "AssociatedResumeObject",
-		Format: "Object",
+		Name: "AssociatedResumeObject",
 	},
 	"26000010": {
-		Name:   "DetectKernelAndHal",
-		Format: "Boolean",
+		Name: "DetectKernelAndHal",
 	},
 	"22000011": {
-		Name:   "KernelPath",
-		Format: "String",
+		Name: "KernelPath",
 	},
 	"22000012": {
-		Name:   "HalPath",
-		Format: "String",
+		Name: "HalPath",
 	},
 	"22000013": {
-		Name:   "DbgTransportPath",
-		Format: "String",
+		Name: "DbgTransportPath",
 	},
 	"25000020": {
-		Name:   "NxPolicy",
-		Format: "Integer",
+		Name: "NxPolicy",
 	},
 	"25000021": {
-		Name:   "PAEPolicy",
-		Format: "Integer",
+		Name: "PAEPolicy",
 	},
 	"26000022": {
-		Name:   "WinPEMode",
-		Format: "Boolean",
+		Name: "WinPEMode",
 	},
 	"26000024": {
-		Name:   "DisableCrashAutoReboot",
-		Format: "Boolean",
+		Name: "DisableCrashAutoReboot",
 	},
 	"26000025": {
-		Name:   "UseLastGoodSettings",
-		Format: "Boolean",
+		Name: "UseLastGoodSettings",
 	},
 	"26000027": {
-		Name:   "AllowPrereleaseSignatures",
-		Format: "Boolean",
+		Name: "AllowPrereleaseSignatures",
 	},
 	"26000030": {
-		Name:   "NoLowMemory",
-		Format: "Boolean",
+		Name: "NoLowMemory",
 	},
 	"25000031": {
-		Name:   "RemoveMemory",
-		Format: "Integer",
+		Name: "RemoveMemory",
 	},
 	"25000032": {
-		Name:   "IncreaseUserVa",
-		Format: "Integer",
+		Name: "IncreaseUserVa",
 	},
 	"26000040": {
-		Name:   "UseVgaDriver",
-		Format: "Boolean",
+		Name: "UseVgaDriver",
 	},
 	"26000041": {
-		Name:   "DisableBootDisplay",
-		Format: "Boolean",
+		Name: "DisableBootDisplay",
 	},
 	"26000042": {
-		Name:   "DisableVesaBios",
-		Format: "Boolean",
+		Name: "DisableVesaBios",
 	},
 	"26000043": {
-		Name:   "DisableVgaMode",
-		Format: "Boolean",
+		Name: "DisableVgaMode",
 	},
 	"25000050": {
-		Name:   "ClusterModeAddressing",
-		Format: "Integer",
+		Name: "ClusterModeAddressing",
 	},
 	"26000051": {
-		Name:   "UsePhysicalDestination",
-		Format: "Boolean",
+		Name: "UsePhysicalDestination",
 	},
 	"25000052": {
-		Name:   "RestrictApicCluster",
-		Format: "Integer",
+		Name: "RestrictApicCluster",
 	},
 	"26000054": {
-		Name:   "UseLegacyApicMode",
-		Format: "Boolean",
+		Name: "UseLegacyApicMode",
 	},
 	"25000055": {
-		Name:   "X2ApicPolicy",
-		Format: "Integer",
+		Name: "X2ApicPolicy",
 	},
 	"26000060": {
-		Name:   "UseBootProcessorOnly",
-		Format: "Boolean",
+		Name: "UseBootProcessorOnly",
 	},
 	"25000061": {
-		Name:   "NumberOfProcessors",
-		Format: "Integer",
+		Name: "NumberOfProcessors",
 	},
 	"26000062": {
-		Name:   "ForceMaximumProcessors",
-		Format: "Boolean",
+		Name: "ForceMaximumProcessors",
 	},
 	"25000063": {
-		Name:   "ProcessorConfigurationFlags",
-		Format: "Boolean",
+		Name: "ProcessorConfigurationFlags",
 	},
 	"26000064": {
-		Name:   "MaximizeGroupsCreated",
-		Format: "Boolean",
+		Name: "MaximizeGroupsCreated",
 	},
 	"26000065": {
-		Name:   "ForceGroupAwareness",
-		Format: "Boolean",
+		Name: "ForceGroupAwareness",
 	},
 	"25000066": {
-		Name:   "GroupSize",
-		Format: "Integer",
+		Name: "GroupSize",
 	},
 	"26000070": {
-		Name:   "UseFirmwarePciSettings",
-		Format: "Integer",
+		Name: "UseFirmwarePciSettings",
 	},
 	"25000071": {
-		Name:   "MsiPolicy",
-		Format: "Integer",
+		Name: "MsiPolicy",
 	},
 	"25000080": {
-		Name:   "SafeBoot",
-		Format: "Integer",
+		Name: "SafeBoot",
 	},
 	"26000081": {
-		Name:   "SafeBootAlternateShell",
-		Format: "Boolean",
+		Name: "SafeBootAlternateShell",
 	},
 	"26000090": {
-		Name:   "BootLogInitialization",
-		Format: "Boolean",
+		Name: "BootLogInitialization",
 	},
 	"26000091": {
-		Name:   "VerboseObjectLoadMode",
-		Format: "Boolean",
+		Name: "VerboseObjectLoadMode",
 	},
 	"260000a0": {
-		Name:   "KernelDebuggerEnabled",
-		Format: "Boolean",
+		Name: "KernelDebuggerEnabled",
 	},
 	"260000a1": {
-		Name:   "DebuggerHalBreakpoint",
-		Format: "Boolean",
+		Name: "DebuggerHalBreakpoint",
 	},
-	"260000A2": {
-		Name:   "UsePlatformClock",
-		Format: "Boolean",
+	"260000a2": {
+		Name: "UsePlatformClock",
 	},
-	"260000A3": {
-		Name:   "ForceLegacyPlatform",
-		Format: "Boolean",
+	"260000a3": {
+		Name: "ForceLegacyPlatform",
 	},
-	"250000A6": {
-		Name:   "TscSyncPolicy",
-		Format: "Integer",
+	"250000a6": {
+		Name: "TscSyncPolicy",
 	},
 	"260000b0": {
-		Name:   "EmsEnabled",
-		Format: "Boolean",
+		Name: "EmsEnabled",
 	},
 	"250000c1": {
-		Name:   "DriverLoadFailurePolicy",
-		Format: "Integer",
+		Name: "DriverLoadFailurePolicy",
 	},
-	"250000C2": {
-		Name:   "BootMenuPolicy",
-		Format: "Integer",
+	"250000c2": {
+		Name: "BootMenuPolicy",
 	},
-	"260000C3": {
-		Name:   "AdvancedOptionsOneTime",
-		Format: "Boolean",
+	"260000c3": {
+		Name: "AdvancedOptionsOneTime",
 	},
-	"250000E0": {
-		Name:   "BootStatusPolicy",
-		Format: "Integer",
+	"250000e0": {
+		Name: "BootStatusPolicy",
 	},
-	"260000E1": {
-		Name:   "DisableElamDrivers",
-		Format: "Boolean",
+	"260000e1": {
+		Name: "DisableElamDrivers",
 	},
-	"250000F0": {
-		Name:   "HypervisorLaunchType",
-		Format: "Integer",
+	"250000f0": {
+		Name: "HypervisorLaunchType",
 	},
-	"260000F2": {
-		Name:   "HypervisorDebuggerEnabled",
-		Format: "Boolean",
+	"260000f2": {
+		Name: "HypervisorDebuggerEnabled",
 	},
-	"250000F3": {
-		Name:   "HypervisorDebuggerType",
-		Format: "Integer",
+	"250000f3": {
+		Name: "HypervisorDebuggerType",
 	},
-	"250000F4": {
-		Name:   "HypervisorDebuggerPortNumber",
-		Format: "Integer",
+	"250000f4": {
+		Name: "HypervisorDebuggerPortNumber",
 	},
-	"250000F5": {
-		Name:   "HypervisorDebuggerBaudrate",
-		Format: "Integer",
+	"250000f5": {
+		Name: "HypervisorDebuggerBaudrate",
 	},
-	"250000F6": {
-		Name:   "HypervisorDebugger1394Channel",
-		Format: "Integer",
+	"250000f6": {
+		Name: "HypervisorDebugger1394Channel",
 	},
-	"250000F7": {
-		Name:   "BootUxPolicy",
-		Format: "Integer",
+	"250000f7": {
+		Name: "BootUxPolicy",
 	},
-	"220000F9": {
-		Name:   "HypervisorDebuggerBusParams",
-		Format: "String",
+	"220000f9": {
+		Name: "HypervisorDebuggerBusParams",
 	},
-	"250000FA": {
-		Name:   "HypervisorNumProc",
-		Format: "Integer",
+	"250000fa": {
+		Name: "HypervisorNumProc",
 	},
-	"250000FB": {
-		Name:   "HypervisorRootProcPerNode",
-		Format: "Integer",
+	"250000fb": {
+		Name: "HypervisorRootProcPerNode",
 	},
-	"260000FC": {
-		Name:   "HypervisorUseLargeVTlb",
-		Format: "Boolean",
+	"260000fc": {
+		Name: "HypervisorUseLargeVTlb",
 	},
-	"250000FD": {
-		Name:   "HypervisorDebuggerNetHostIp",
-		Format: "Integer",
+	"250000fd": {
+		Name: "HypervisorDebuggerNetHostIp",
 	},
-	"250000FE": {
-		Name:   "HypervisorDebuggerNetHostPort",
-		Format: "Integer",
+	"250000fe": {
+		Name: "HypervisorDebuggerNetHostPort",
 	},
 	"25000100": {
-		Name:   "TpmBootEntropyPolicy",
-		Format: "Integer",
+		Name: "TpmBootEntropyPolicy",
 	},
 	"22000110": {
-		Name:   "HypervisorDebuggerNetKey",
-		Format: "String",
+		Name: "HypervisorDebuggerNetKey",
 	},
 	"26000114": {
-		Name:   "HypervisorDebuggerNetDhcp",
-		Format: "Boolean",
+		Name: "HypervisorDebuggerNetDhcp",
 	},
 	"25000115": {
-		Name:   "HypervisorIommuPolicy",
-		Format: "Integer",
+		Name: "HypervisorIommuPolicy",
 	},
 	"2500012b": {
-		Name:   "XSaveDisable",
-		Format: "Integer",
+		Name: "XSaveDisable",
 	},
 }
 
 var BcdApplicationElementTypes = map[ApplicationType]map[string]*BcdElementMeta{}
 
 func init() {
+	for _, elementTypes := range []map[string]*BcdElementMeta{
+		GenericElementTypes,
+		BcdBootMgrElementTypes,
+		BcdDeviceElementTypes,
+		BcdOsLoaderElementTypes,
+	} {
+		for key, meta := range elementTypes {
+			elementType, err := ParseElementType(key)
+			if err != nil {
+				panic(err)
+			}
+			meta.Format = elementType.Format().String()
+		}
+	}
 	BcdApplicationElementTypes[ApplicationBootmgr] = concatBcdElementTypes(
 		GenericElementTypes,
 		BcdBootMgrElementTypes,
@@ -531,3 +451,21 @@ func concatBcdElementTypes(inputs ...map[string]*BcdElementMeta) map[string]*Bcd
 	}
 	return concated
 }
+
+// Well-known element types.
+const (
+	ElementApplicationDevice      ElementType = 0x11000001
+	ElementApplicationPath        ElementType = 0x12000002
+	ElementDescription            ElementType = 0x12000004
+	ElementInheritedObjects       ElementType = 0x14000006
+	ElementRecoverySequence       ElementType = 0x14000008
+	ElementOsDevice               ElementType = 0x21000001
+	ElementSystemRoot             ElementType = 0x22000002
+	ElementAssociatedResumeObject ElementType = 0x23000003
+	ElementDisplayOrder           ElementType = 0x24000001
+	ElementBootSequence           ElementType = 0x24000002
+	ElementDefaultObject          ElementType = 0x23000003
+	ElementTimeout                ElementType = 0x25000004
+	ElementResumeObject           ElementType = 0x23000006
+	ElementToolsDisplayOrder      ElementType = 0x24000010
+)
@@ -2,11 +2,12 @@ package go_bcdedit
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	"github.com/gabriel-samfira/go-hivex"
 	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
 	"github.com/jc-lab/go-bcdedit/pkg/hiveutil"
 	"slices"
 	"strings"
@@ -16,17 +17,36 @@ import (
 type BcdElement interface {
 	GetType() ValueType
 	GetRaw() []byte
+	GetBoolean() (bool, error)
+	GetInteger() (uint64, error)
+	GetIntegerList() ([]uint64, error)
+	GetObject() (string, error)
+	GetObjectList() ([]string, error)
+	GetDevice() (*model.Device, error)
 }
 
 type BcdObject interface {
 	GetId() string
 	GetDescription() model.BcdDescription
-	GetElements() map[string]BcdElement
+	GetElements() map[model.ElementType]BcdElement
 	SortedElements() []BcdElement
-	SetElement(key string, typ ValueType, raw []byte) (BcdElement, error)
+	SetElement(key model.ElementType, typ ValueType, raw []byte) (BcdElement, error)
+	SetString(key model.ElementType, value string) (BcdElement, error)
+	SetBoolean(key model.ElementType, value bool) (BcdElement, error)
+	SetInteger(key model.ElementType, value uint64) (BcdElement, error)
+	SetIntegerList(key model.ElementType, values []uint64) (BcdElement, error)
+	SetObject(key model.ElementType, objectId string) (BcdElement, error)
+	SetObjectList(key model.ElementType, objectIds []string) (BcdElement, error)
+	SetDevice(key model.ElementType, device *model.Device) (BcdElement, error)
+	DeleteElement(key model.ElementType) error
 	ToJson() *model.BcdObject
 }
 
+const (
+	BootMgrObjectId   = "{9dea862c-5cdd-4e70-acc1-f32b344d4795}"
+	FwBootMgrObjectId = "{a5a30fa2-3d06-4e9f-b5f4-a01df9d1fcba}"
+)
+
 // KnownObjectIds BCD.docx, page 9: Standard application Objects
 var KnownObjectIds = map[string]string{
 	"{9dea862c-5cdd-4e70-acc1-f32b344d4795}": "{bootmgr}",   // 0x10100002
@@ -40,25 +60,44 @@ var KnownObjectIds = map[string]string{
 	"{0ce4991b-e6b3-4b16-b23c-5e0d9250e5d9}": "{emssettings}",
 	"{7ea2e1ac-2e61-4728-aaa3-896d9d0a9f0e}": "{globalsettings}",
 	"{1afa9c49-16ab-4a5c-901b-212802da9460}": "{resumeloadersettings}",
+	"{ae5534e0-a924-466c-b836-758539a3ee3a}": "{ramdiskoptions}",
+	"{7ff607e0-4395-11db-b0de-0800200c9a66}": "{hypervisorsettings}",
 }
 
-func (e *HiveBcdElement) Meta() *model.BcdElementMeta {
-	var elementTypes map[string]*model.BcdElementMeta
-	switch e.Parent.Description.ObjectType() {
+// WellKnownObjectIds maps the aliases of KnownObjectIds back to their ids.
+// {current} is left out since it never names a stored object.
+var WellKnownObjectIds = map[string]string{}
+
+func init() {
+	for id, alias := range KnownObjectIds {
+		if alias != CurrentAlias {
+			WellKnownObjectIds[alias] = id
+		}
+	}
+}
+
+// ElementTypes returns the element metadata applicable to the object's type.
+func (o *HiveBcdObject) ElementTypes() map[string]*model.BcdElementMeta {
+	switch o.Description.ObjectType() {
 	case model.ObjectApplication:
-		elementTypes = model.BcdApplicationElementTypes[e.Parent.Description.ApplicationType()]
+		return model.BcdApplicationElementTypes[o.Description.ApplicationType()]
 	case model.ObjectDevice:
-		elementTypes = model.BcdDeviceElementTypes
+		return model.BcdDeviceElementTypes
 	case model.ObjectInherit:
-		switch e.Parent.Description.ObjectSubType() {
+		switch o.Description.ObjectSubType() {
 		case model.InheritableByApplicationObjects:
-			elementTypes = model.BcdApplicationElementTypes[e.Parent.Description.ApplicationType()]
+			return model.BcdApplicationElementTypes[o.Description.ApplicationType()]
 		case model.InheritableByDeviceObjects:
-			elementTypes = model.BcdDeviceElementTypes
+			return model.BcdDeviceElementTypes
 		}
 	}
+	return nil
+}
+
+func (e *HiveBcdElement) Meta() *model.BcdElementMeta {
+	elementTypes := e.Parent.ElementTypes()
 	if elementTypes != nil {
-		return elementTypes[e.Key]
+		return elementTypes[e.Key.String()]
 	}
 	return nil
 }
@@ -125,6 +164,15 @@ func (e *HiveBcdElement) String() string {
 		if err != nil {
 			return fmt.Sprintf("ERROR: %+v", err)
 		}
+	case RegBinary:
+		if e.Key.Format() != model.ElementFormatDevice {
+			return fmt.Sprintf("Type=%v, Raw=%s", e.Type, hex.EncodeToString(e.Raw))
+		}
+		device, err := e.GetDevice()
+		if err != nil {
+			return fmt.Sprintf("ERROR: %+v", err)
+		}
+		results = append(results, device.String())
 	default:
 		return fmt.Sprintf("Type=%v, Raw=%s", e.Type, hex.EncodeToString(e.Raw))
 	}
@@ -139,23 +187,32 @@ func (o *HiveBcdObject) SortedElements() []BcdElement {
 	slices.SortFunc(sortedElements, func(a, b BcdElement) int {
 		ha := a.(*HiveBcdElement)
 		hb := b.(*HiveBcdElement)
-		return strings.Compare(ha.Key, hb.Key)
+		return cmp.Compare(ha.Key, hb.Key)
 	})
 	return sortedElements
 }
 
-func (o *HiveBcdObject) SetElement(key string, typ ValueType, raw []byte) (BcdElement, error) {
-	elementNode, err := hiveutil.UpsertNode(o.Bcd.Hive, o.ElementsNode, key)
-	if err != nil {
-		return nil, err
+func (o *HiveBcdObject) SetElement(key model.ElementType, typ ValueType, raw []byte) (BcdElement, error) {
+	var elementNode int64
+	if existing, ok := o.Elements[key]; ok {
+		elementNode = existing.Node
+	} else {
+		var err error
+		elementNode, err = hiveutil.UpsertNode(o.Bcd.Hive, o.ElementsNode, key.String())
+		if err != nil {
+			return nil, err
+		}
 	}
-	_, err = o.Bcd.Hive.NodeSetValue(elementNode, hivex.HiveValue{
+	_, err := o.Bcd.Hive.NodeSetValue(elementNode, hive.Value{
 		Type:  int(typ),
 		Key:   "Element",
 		Value: raw,
 	})
+	if err != nil {
+		return nil, err
+	}
 	e := &HiveBcdElement{
-		Parent: nil,
+		Parent: o,
 		Node:   elementNode,
 		Key:    key,
 		Type:   typ,
@@ -165,6 +222,19 @@ func (o *HiveBcdObject) SetElement(key string, typ ValueType, raw []byte) (BcdEl
 	return e, nil
 }
 
+func (o *HiveBcdObject) DeleteElement(key model.ElementType) error {
+	existing, ok := o.Elements[key]
+	if !ok {
+		return fmt.Errorf("not exists %s\\Elements\\%s", o.Id, key)
+	}
+	_, err := o.Bcd.Hive.NodeDeleteChild(existing.Node)
+	if err != nil {
+		return err
+	}
+	delete(o.Elements, key)
+	return nil
+}
+
 func Utf16LEToString(b []byte) (int, string, error) {
 	if len(b)%2 != 0 {
 		return 0, "", fmt.Errorf("invalid UTF-16 LE byte array length: %d", len(b))
@@ -0,0 +1,105 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hiveutil"
+	"testing"
+)
+
+func TestDeleteObject(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	if err := bcd.DeleteObject(testLoaderId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bcd.GetObject(testLoaderId); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("deleted object: got %v, want ErrObjectNotFound", err)
+	}
+	if err := bcd.DeleteObject(testLoaderId); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("deleting twice: got %v, want ErrObjectNotFound", err)
+	}
+	if _, err := bcd.GetObject("{bootmgr}"); err != nil {
+		t.Errorf("other objects are kept: %v", err)
+	}
+}
+
+func TestDeleteElement(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	object := mustGetObject(t, bcd, testLoaderId)
+	if err := object.DeleteElement(model.ElementDescription); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := object.GetElements()[model.ElementDescription]; ok {
+		t.Error("element still listed by the object it was deleted from")
+	}
+	if _, ok := mustGetObject(t, bcd, testLoaderId).GetElements()[model.ElementDescription]; ok {
+		t.Error("element still stored")
+	}
+	if err := object.DeleteElement(model.ElementDescription); err == nil {
+		t.Error("deleting a missing element: expected an error")
+	}
+}
+
+func TestCopyObject(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	src := mustGetObject(t, bcd, testLoaderId)
+	mustSet(t)(src.SetInteger(0x25000020, 1))
+	object, err := bcd.CopyObject("{default}", "", "Copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = model.ParseObjectId(object.GetId()); err != nil || object.GetId() == testLoaderId {
+		t.Errorf("copied to %s, %v", object.GetId(), err)
+	}
+	copied := mustGetObject(t, bcd, object.GetId())
+	if copied.GetDescription() != osloaderType || len(copied.GetElements()) != len(src.GetElements()) {
+		t.Errorf("copy has type %08x and %d elements", uint32(copied.GetDescription()), len(copied.GetElements()))
+	}
+	if got := description(t, bcd, object.GetId()); got != "Copy" {
+		t.Errorf("copy described %q", got)
+	}
+	if got := description(t, bcd, testLoaderId); got != "Windows" {
+		t.Errorf("source described %q", got)
+	}
+
+	if _, err = bcd.CopyObject(testLoaderId, testOtherId, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := description(t, bcd, testOtherId); got != "Windows" {
+		t.Errorf("copy without a description described %q", got)
+	}
+	if _, err = bcd.CopyObject(testLoaderId, testOtherId, ""); err == nil {
+		t.Error("copy onto an existing object: expected an error")
+	}
+	if _, err = bcd.CopyObject(testOtherId+"x", "", ""); err == nil {
+		t.Error("copy of an invalid id: expected an error")
+	}
+}
+
+func TestCopyObjectOntoUnreadableObject(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	// an object key without its Description key cannot be read, but exists
+	h := bcd.(*HiveBcdedit).Hive
+	objects, err := hiveutil.GetObjectsNode(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := h.NodeAddChild(objects, testOtherId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bcd.CopyObject(testLoaderId, testOtherId, ""); err == nil || errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("got %v, want the read error", err)
+	}
+	if child, _ := hiveutil.FindChild(h, node, "Description"); child != 0 {
+		t.Error("the unreadable object was overwritten")
+	}
+}
@@ -0,0 +1,136 @@
+package go_bcdedit
+
+import (
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"strconv"
+	"strings"
+)
+
+// ResolveOption resolves a bcdedit option name (e.g. "nx"), an element name
+// (e.g. "NxPolicy") or a hex element type (e.g. "25000020") for the object.
+func ResolveOption(object BcdObject, name string) (model.ElementType, error) {
+	application := object.GetDescription().ApplicationType()
+	if option := model.FindOption(name, application); option != nil {
+		return option.Type, nil
+	}
+	if key, err := model.ParseElementType(name); err == nil {
+		return key, nil
+	}
+	if typed, ok := object.(interface {
+		ElementTypes() map[string]*model.BcdElementMeta
+	}); ok {
+		for key, meta := range typed.ElementTypes() {
+			if strings.EqualFold(meta.Name, name) {
+				return model.ParseElementType(key)
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown element: %s", name)
+}
+
+// SetOptionValue parses bcdedit style arguments according to the element
+// format and sets the element, e.g. "OptIn" for nx, "30" for timeout,
+// "on" for testsigning or "{a} {b}" for displayorder. Identifiers are
+// resolved through bcd, so aliases like {bootmgr} or {default} work.
+func SetOptionValue(bcd Bcdedit, object BcdObject, key model.ElementType, args []string) (BcdElement, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("element %s needs a value", key)
+	}
+	switch key.Format() {
+	case model.ElementFormatDevice:
+		device, err := ParseDeviceString(strings.Join(args, " "))
+		if err != nil {
+			return nil, err
+		}
+		return object.SetDevice(key, device)
+
+	case model.ElementFormatString:
+		return object.SetString(key, strings.Join(args, " "))
+
+	case model.ElementFormatObject:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("element %s takes a single identifier", key)
+		}
+		id, err := bcd.ResolveObjectId(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return object.SetObject(key, id)
+
+	case model.ElementFormatObjectList:
+		var ids []string
+		for _, arg := range args {
+			id, err := bcd.ResolveObjectId(arg)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return object.SetObjectList(key, ids)
+
+	case model.ElementFormatInteger:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("element %s takes a single integer", key)
+		}
+		option := model.FindOptionByType(key, object.GetDescription().ApplicationType())
+		if option != nil {
+			if value, ok := option.ParseValue(args[0]); ok {
+				return object.SetInteger(key, value)
+			}
+		}
+		value, err := parseInteger(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return object.SetInteger(key, value)
+
+	case model.ElementFormatBoolean:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("element %s takes a single boolean", key)
+		}
+		value, err := ParseBoolean(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return object.SetBoolean(key, value)
+
+	case model.ElementFormatIntegerList:
+		var values []uint64
+		for _, arg := range args {
+			value, err := parseInteger(arg)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return object.SetIntegerList(key, values)
+	}
+	return nil, fmt.Errorf("element %s has unknown format", key)
+}
+
+// ParseBoolean accepts the bcdedit boolean spellings on/off, yes/no,
+// true/false and 1/0.
+func ParseBoolean(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "yes", "true", "1":
+		return true, nil
+	case "off", "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean: %s", s)
+}
+
+func parseInteger(s string) (uint64, error) {
+	var n uint64
+	var err error
+	if strings.HasPrefix(s, "0x") {
+		n, err = strconv.ParseUint(s[2:], 16, 64)
+	} else {
+		n, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer: %s", s)
+	}
+	return n, nil
+}
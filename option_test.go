@@ -0,0 +1,101 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"reflect"
+	"testing"
+)
+
+func TestResolveOption(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	loader := mustGetObject(t, bcd, testLoaderId)
+	tests := []struct {
+		name string
+		want model.ElementType
+	}{
+		{"nx", 0x25000020},
+		{"NxPolicy", 0x25000020},
+		{"25000020", 0x25000020},
+		{"description", model.ElementDescription},
+	}
+	for _, test := range tests {
+		if got, err := ResolveOption(loader, test.name); err != nil || got != test.want {
+			t.Errorf("%s: got %s, %v, want %s", test.name, got, err, test.want)
+		}
+	}
+	if _, err := ResolveOption(loader, "nosuchoption"); err == nil {
+		t.Error("unknown option: expected an error")
+	}
+	// timeout is a boot manager option.
+	if _, err := ResolveOption(loader, "timeout"); err == nil {
+		t.Error("timeout on a loader: expected an error")
+	}
+}
+
+func TestSetOptionValue(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	manager := mustGetObject(t, bcd, "{bootmgr}")
+	loader := mustGetObject(t, bcd, testLoaderId)
+	if _, err := bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(object BcdObject, name string, args ...string) BcdElement {
+		t.Helper()
+		key, err := ResolveOption(object, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		element, err := SetOptionValue(bcd, object, key, args)
+		if err != nil {
+			t.Fatalf("%s %q: %v", name, args, err)
+		}
+		return element
+	}
+
+	if value, err := set(loader, "nx", "OptIn").GetInteger(); err != nil || value != 0 {
+		t.Errorf("nx OptIn: %d, %v", value, err)
+	}
+	if value, err := set(loader, "nx", "AlwaysOff").GetInteger(); err != nil || value != 2 {
+		t.Errorf("nx AlwaysOff: %d, %v", value, err)
+	}
+	if value, err := set(manager, "timeout", "0x10").GetInteger(); err != nil || value != 16 {
+		t.Errorf("timeout: %d, %v", value, err)
+	}
+	if value, err := set(loader, "testsigning", "on").GetBoolean(); err != nil || !value {
+		t.Errorf("testsigning: %v, %v", value, err)
+	}
+	if value, err := set(loader, "description", "Windows", "10").(*HiveBcdElement).GetString(); err != nil || value != "Windows 10" {
+		t.Errorf("description: %q, %v", value, err)
+	}
+	if values, err := set(manager, "displayorder", testOtherId, testLoaderId).GetObjectList(); err != nil || !reflect.DeepEqual(values, []string{testOtherId, testLoaderId}) {
+		t.Errorf("displayorder: %q, %v", values, err)
+	}
+
+	key, _ := ResolveOption(loader, "testsigning")
+	if _, err := SetOptionValue(bcd, loader, key, []string{"maybe"}); err == nil {
+		t.Error("invalid boolean: expected an error")
+	}
+	if _, err := SetOptionValue(bcd, loader, key, nil); err == nil {
+		t.Error("missing value: expected an error")
+	}
+	key, _ = ResolveOption(manager, "timeout")
+	if _, err := SetOptionValue(bcd, manager, key, []string{"-1"}); err == nil {
+		t.Error("negative integer: expected an error")
+	}
+}
+
+func TestParseBoolean(t *testing.T) {
+	for input, want := range map[string]bool{"on": true, "Yes": true, "TRUE": true, "1": true, "off": false, "no": false, "false": false, "0": false} {
+		if got, err := ParseBoolean(input); err != nil || got != want {
+			t.Errorf("%s: got %v, %v", input, got, err)
+		}
+	}
+	if _, err := ParseBoolean("2"); err == nil {
+		t.Error("2: expected an error")
+	}
+}
@@ -0,0 +1,242 @@
+package bcdedit_cmd
+
+import (
+	"cmp"
+	"fmt"
+	go_bcdedit "github.com/jc-lab/go-bcdedit"
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+)
+
+// applicationTitles are the section titles bcdedit prints per application type.
+var applicationTitles = map[model.ApplicationType]string{
+	model.ApplicationFwbootmgr:  "Firmware Boot Manager",
+	model.ApplicationBootmgr:    "Windows Boot Manager",
+	model.ApplicationOsloader:   "Windows Boot Loader",
+	model.ApplicationResume:     "Resume from Hibernate",
+	model.ApplicationMemdiag:    "Windows Memory Tester",
+	model.ApplicationNtldr:      "Windows Legacy OS Loader",
+	model.ApplicationSetupldr:   "Windows Setup Loader",
+	model.ApplicationBootsector: "Real-mode Boot Sector",
+	model.ApplicationStartup:    "Real-mode Startup",
+	model.ApplicationBootapp:    "Boot Application",
+}
+
+// settingsObjects are the inheritable objects bcdedit titles by identifier,
+// in the order /enum all lists them.
+var settingsObjects = []struct {
+	Alias string
+	Title string
+}{
+	{"{emssettings}", "EMS Settings"},
+	{"{dbgsettings}", "Debugger Settings"},
+	{"{badmemory}", "RAM Defects"},
+	{"{globalsettings}", "Global Settings"},
+	{"{bootloadersettings}", "Boot Loader Settings"},
+	{"{hypervisorsettings}", "Hypervisor Settings"},
+	{"{resumeloadersettings}", "Resume Loader Settings"},
+}
+
+// enumPrinter renders objects the way bcdedit /enum does.
+type enumPrinter struct {
+	bcd     go_bcdedit.Bcdedit
+	aliases map[string]string // lowercase id to displayed alias, empty with /v
+}
+
+func newEnumPrinter(bcd go_bcdedit.Bcdedit, verbose bool) *enumPrinter {
+	p := &enumPrinter{
+		bcd:     bcd,
+		aliases: make(map[string]string),
+	}
+	if verbose {
+		return p
+	}
+	for id, alias := range go_bcdedit.KnownObjectIds {
+		p.aliases[id] = alias
+	}
+	// {current} wins over {default} when both name the same entry
+	for _, alias := range []string{go_bcdedit.DefaultAlias, go_bcdedit.CurrentAlias} {
+		if id, err := bcd.ResolveObjectId(alias); err == nil {
+			p.aliases[strings.ToLower(id)] = alias
+		}
+	}
+	return p
+}
+
+func (p *enumPrinter) objectId(id string) string {
+	if alias, ok := p.aliases[strings.ToLower(id)]; ok {
+		return alias
+	}
+	return id
+}
+
+func (p *enumPrinter) title(object go_bcdedit.BcdObject) string {
+	description := object.GetDescription()
+	switch description.ObjectType() {
+	case model.ObjectApplication:
+		if go_bcdedit.IsFirmwareEntry(description) {
+			return fmt.Sprintf("Firmware Application (%x)", uint32(description))
+		}
+		if title, ok := applicationTitles[description.ApplicationType()]; ok {
+			return title
+		}
+	case model.ObjectInherit:
+		alias := go_bcdedit.KnownObjectIds[strings.ToLower(object.GetId())]
+		for _, settings := range settingsObjects {
+			if settings.Alias == alias {
+				return settings.Title
+			}
+		}
+		return "Inherited Settings"
+	case model.ObjectDevice:
+		return "Device options"
+	}
+	return fmt.Sprintf("Unknown Object (%08x)", uint32(description))
+}
+
+// rank orders objects without a display order position: applications by
+// type, then settings objects as bcdedit lists them, then devices.
+func (p *enumPrinter) rank(object go_bcdedit.BcdObject) int {
+	description := object.GetDescription()
+	switch description.ObjectType() {
+	case model.ObjectApplication:
+		if go_bcdedit.IsFirmwareEntry(description) {
+			return 0
+		}
+		return int(description.ApplicationType())
+	case model.ObjectInherit:
+		alias := go_bcdedit.KnownObjectIds[strings.ToLower(object.GetId())]
+		for i, settings := range settingsObjects {
+			if settings.Alias == alias {
+				return 0x100000 + i
+			}
+		}
+		return 0x100000 + len(settingsObjects)
+	case model.ObjectDevice:
+		return 0x200000
+	}
+	return 0x300000
+}
+
+// sort puts the boot managers first, followed by the entries in their
+// display orders and then everything else by type and identifier.
+func (p *enumPrinter) sort(objects []go_bcdedit.BcdObject) {
+	position := make(map[string]int)
+	add := func(id string) {
+		id = strings.ToLower(id)
+		if _, ok := position[id]; !ok {
+			position[id] = len(position)
+		}
+	}
+	for _, managerId := range []string{go_bcdedit.FwBootMgrObjectId, go_bcdedit.BootMgrObjectId} {
+		manager, err := p.bcd.GetObject(managerId)
+		if err != nil {
+			continue
+		}
+		add(managerId)
+		if element, ok := manager.GetElements()[model.ElementDisplayOrder]; ok {
+			ids, _ := element.GetObjectList()
+			for _, id := range ids {
+				add(id)
+			}
+		}
+	}
+
+	slices.SortStableFunc(objects, func(a, b go_bcdedit.BcdObject) int {
+		aPosition, aListed := position[strings.ToLower(a.GetId())]
+		bPosition, bListed := position[strings.ToLower(b.GetId())]
+		switch {
+		case aListed && bListed:
+			return cmp.Compare(aPosition, bPosition)
+		case aListed:
+			return -1
+		case bListed:
+			return 1
+		}
+		if c := cmp.Compare(p.rank(a), p.rank(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(strings.ToLower(a.GetId()), strings.ToLower(b.GetId()))
+	})
+}
+
+// elementName returns the bcdedit option name, the lowercase element name
+// for elements bcdedit has no option for, or custom:<type>.
+func (p *enumPrinter) elementName(object go_bcdedit.BcdObject, key model.ElementType) string {
+	if option := model.FindOptionByType(key, object.GetDescription().ApplicationType()); option != nil {
+		return option.Name
+	}
+	if typed, ok := object.(interface {
+		ElementTypes() map[string]*model.BcdElementMeta
+	}); ok {
+		if meta := typed.ElementTypes()[key.String()]; meta != nil {
+			return strings.ToLower(meta.Name)
+		}
+	}
+	return "custom:" + key.String()
+}
+
+func (p *enumPrinter) elementValue(object go_bcdedit.BcdObject, key model.ElementType, element go_bcdedit.BcdElement) string {
+	switch key.Format() {
+	case model.ElementFormatObject:
+		if id, err := element.GetObject(); err == nil {
+			return p.objectId(id)
+		}
+	case model.ElementFormatObjectList:
+		if ids, err := element.GetObjectList(); err == nil {
+			var lines []string
+			for _, id := range ids {
+				lines = append(lines, p.objectId(id))
+			}
+			return strings.Join(lines, "\n")
+		}
+	case model.ElementFormatDevice:
+		if device, err := element.GetDevice(); err == nil {
+			if device.OptionsId != "" {
+				device.OptionsId = p.objectId(device.OptionsId)
+			}
+			return device.String()
+		}
+	}
+	return go_bcdedit.FormatElementValue(key, object.GetDescription().ApplicationType(), element)
+}
+
+func (p *enumPrinter) printLine(name string, value string) {
+	fmt.Printf("%s %s\n", StringWithPad(name), StringWithNewLinePad(value))
+}
+
+// printObject prints the title, identifier and elements of the object, or
+// its effective elements after following the inherit list.
+func (p *enumPrinter) printObject(object go_bcdedit.BcdObject, effective bool) error {
+	title := p.title(object)
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("-", len(title)))
+	p.printLine("identifier", p.objectId(object.GetId()))
+
+	if !effective {
+		elements := object.GetElements()
+		var keys []model.ElementType
+		for key := range elements {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			p.printLine(p.elementName(object, key), p.elementValue(object, key, elements[key]))
+		}
+		return nil
+	}
+
+	elements, err := go_bcdedit.EffectiveElements(p.bcd, object.GetId())
+	if err != nil {
+		return err
+	}
+	for _, element := range go_bcdedit.SortedEffectiveElements(elements) {
+		value := p.elementValue(object, element.Key, element.Element)
+		if !strings.EqualFold(element.Source, object.GetId()) {
+			value += "  (inherited from " + p.objectId(element.Source) + ")"
+		}
+		p.printLine(p.elementName(object, element.Key), value)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package bcdedit_cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	go_bcdedit "github.com/jc-lab/go-bcdedit"
+	"github.com/jc-lab/go-bcdedit/model"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what fn prints to standard output.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	output := <-done
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes.ReplaceAll(output, []byte("\r\n"), []byte("\n")))
+}
+
+const testSettingsId = "{6efb52bf-1766-41db-a6b3-0ee5eff72bd7}" // {bootloadersettings}
+
+const enumOutput = `Windows Boot Manager
+--------------------
+identifier              {bootmgr}
+default                 {default}
+displayorder            {default}
+                        {66666666-7777-4888-9999-aaaaaaaaaaaa}
+timeout                 30
+
+Windows Boot Loader
+-------------------
+identifier              {default}
+device                  boot
+description             Windows
+inherit                 {bootloadersettings}
+testsigning             Yes
+
+Windows Boot Loader
+-------------------
+identifier              {66666666-7777-4888-9999-aaaaaaaaaaaa}
+description             Other
+
+Boot Loader Settings
+--------------------
+identifier              {bootloadersettings}
+bootdebug               Yes
+
+`
+
+func TestEnumOutput(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+	prepareEnumStore(t, bcd)
+
+	if got := captureStdout(t, func() error { return doEnum(&Flags{}, bcd) }); got != enumOutput {
+		t.Errorf("got\n%s\nwant\n%s", got, enumOutput)
+	}
+
+	verbose := strings.NewReplacer(
+		"{bootmgr}", go_bcdedit.BootMgrObjectId,
+		"{default}", testLoaderId,
+		"{bootloadersettings}", testSettingsId,
+	).Replace(enumOutput)
+	if got := captureStdout(t, func() error { return doEnum(&Flags{EnumVerbose: true}, bcd) }); got != verbose {
+		t.Errorf("/v: got\n%s\nwant\n%s", got, verbose)
+	}
+
+	effective := strings.Replace(enumOutput, "testsigning             Yes\n",
+		"bootdebug               Yes  (inherited from {bootloadersettings})\ntestsigning             Yes\n", 1)
+	if got := captureStdout(t, func() error { return doEnum(&Flags{EnumEffective: true}, bcd) }); got != effective {
+		t.Errorf("/effective: got\n%s\nwant\n%s", got, effective)
+	}
+}
+
+// prepareEnumStore adds a timeout, {bootloadersettings} and a few loader
+// elements to the test store.
+func prepareEnumStore(t *testing.T, bcd go_bcdedit.Bcdedit) {
+	t.Helper()
+	manager, err := bcd.GetObject("{bootmgr}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := bcd.UpsertObject(testSettingsId, model.BcdDescriptionFrom(model.ObjectInherit, model.InheritableByApplicationObjects, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader, err := bcd.GetObject(testLoaderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := func(_ go_bcdedit.BcdElement, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(manager.SetInteger(model.ElementTimeout, 30))
+	must(settings.SetBoolean(0x16000010, true))
+	must(loader.SetDevice(model.ElementApplicationDevice, model.NewBootDevice()))
+	must(loader.SetBoolean(0x16000049, true))
+	must(loader.SetObjectList(model.ElementInheritedObjects, []string{testSettingsId}))
+}
+
+func TestEnumJsonFilter(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	flags := &Flags{Json: true, Enum: go_bcdedit.EnumerateOptions{
+		Types: []go_bcdedit.EnumType{go_bcdedit.EnumBootmgr},
+		Ids:   []string{testOtherId},
+	}}
+	output := captureStdout(t, func() error { return doEnum(flags, bcd) })
+	var response model.EnumerateResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if len(response.Objects) != 2 || response.Objects[go_bcdedit.BootMgrObjectId] == nil || response.Objects[testOtherId] == nil {
+		t.Errorf("got %s", output)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"github.com/pkg/errors"
 	"log"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ArrayFlags []string
@@ -33,8 +35,13 @@ type Flags struct {
 	Json        bool
 	CreateStore string
 	Store       string
+	LockTimeout time.Duration
+	Aliases     string
+	Current     string
 
-	Enum string
+	Enum          go_bcdedit.EnumerateOptions
+	EnumEffective bool
+	EnumVerbose   bool
 
 	CreateId          string
 	ObjectDescription string
@@ -46,6 +53,52 @@ type Flags struct {
 	SetValueType string
 	SetValueRaw  string
 	SetValue     ArrayFlags
+
+	DeleteId      string
+	DeleteCleanup bool
+
+	DeleteValueId  string
+	DeleteValueKey string
+
+	CopyId string
+
+	ImportClean bool
+	ApplyPrune  bool
+
+	ListOperation go_bcdedit.ListOperation
+	Firmware      bool
+}
+
+func (f *Flags) LockOptions() go_bcdedit.LockOptions {
+	return go_bcdedit.LockOptions{
+		Blocking: f.LockTimeout > 0,
+		Timeout:  f.LockTimeout,
+	}
+}
+
+// AliasResolver loads the alias config file, if any, and applies --current.
+func (f *Flags) AliasResolver() (*go_bcdedit.AliasResolver, error) {
+	resolver := &go_bcdedit.AliasResolver{}
+	file := f.Aliases
+	if file == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			file = filepath.Join(dir, "go-bcdedit", "aliases.json")
+			if _, err := os.Stat(file); err != nil {
+				file = ""
+			}
+		}
+	}
+	if file != "" {
+		var err error
+		resolver, err = go_bcdedit.LoadAliasConfig(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.Current != "" {
+		resolver.Current = f.Current
+	}
+	return resolver, nil
 }
 
 type commandDefine struct {
@@ -64,25 +117,52 @@ var commands = map[string]commandDefine{
 		},
 	},
 	"enum": {
-		Usage:    "/enum all\nThis command lists entries in a store.",
+		Usage: "/enum [<type> | <id> ...] [/effective] [/v]\n" +
+			"This command lists entries in a store. <type> is one of active (the default), all, firmware, bootapp,\n" +
+			"bootmgr, osloader, resume, inherit or device; several types and identifiers can be combined.\n" +
+			"/effective lists the settings applied to the entry after following its inherit list.\n" +
+			"/v shows identifiers in full instead of using names such as {bootmgr} or {default}.",
 		Writable: 0,
 		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
-			flags.Enum = args[0]
+			var flagArgs []string
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "-") {
+					flagArgs = append(flagArgs, arg)
+				} else if t, ok := go_bcdedit.ParseEnumType(arg); ok {
+					flags.Enum.Types = append(flags.Enum.Types, t)
+				} else {
+					flags.Enum.Ids = append(flags.Enum.Ids, arg)
+				}
+			}
+			if len(flags.Enum.Types) == 0 && len(flags.Enum.Ids) == 0 {
+				flags.Enum.Types = []go_bcdedit.EnumType{go_bcdedit.EnumActive}
+			}
+
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.BoolVar(&flags.EnumEffective, "effective", false, "")
+			subFlagset.BoolVar(&flags.EnumVerbose, "v", false, "")
+			subFlagset.Parse(flagArgs)
+
 			return doEnum(flags, bcd)
 		},
 	},
 
 	// bcdedit /store BCD --create {4e2a0b91-5003-47c6-b00b-e496f2d66a11} -object-type 0x10200002
 	"create": {
-		Usage:    "/create <id> --object-type <object type(e.g. 0x10200002)> [/d <description>]\nThis command creates a new entry in the boot configuration data store.",
+		Usage: "/create [<id>] --object-type <object type(e.g. 0x10200002)> [/d <description>]\n" +
+			"This command creates a new entry in the boot configuration data store.\n" +
+			"Without <id> a new identifier is generated and printed.",
 		Writable: 1,
 		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
-			flags.CreateId = args[0]
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				flags.CreateId = args[0]
+				args = args[1:]
+			}
 
 			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
 			subFlagset.UintVar(&flags.CreateDescription, "object-type", 0, "")
 			subFlagset.StringVar(&flags.ObjectDescription, "d", "", "description")
-			subFlagset.Parse(args[1:])
+			subFlagset.Parse(args)
 
 			return doCreateObject(flags, bcd)
 		},
@@ -91,37 +171,393 @@ var commands = map[string]commandDefine{
 	// bcdedit /store BCD /set {ObjectId} --value-type RegSz --value-raw "AAAA"
 	// bcdedit /store BCD /set {ObjectId} --value-type RegSz --value "Hello"
 	// bcdedit /store BCD /set {ObjectId} --value-type RegMultiSz --value "First" --value "Second"
+	// bcdedit /store BCD /set {ObjectId} Device partition=gpt:{DiskId}:{PartitionId}
+	// bcdedit /store BCD /set {ObjectId} nx OptIn
+	// bcdedit /store BCD /set {bootmgr} displayorder {ObjectId1} {ObjectId2}
 	"set": {
-		Usage: "/set <id> --value-type <ValueType(e.g. RegSz)> --value-raw \"BASE64\"\n" +
-			"/set <id> --value-type <ValueType(e.g. RegMultiSz)> --value \"first\" --value \"second\"\n" +
+		Usage: "/set <id> <element> [--value-type <ValueType(e.g. RegSz)>] --value-raw \"BASE64\"\n" +
+			"/set <id> <element> [--value-type <ValueType(e.g. RegMultiSz)>] --value \"first\" --value \"second\"\n" +
+			"/set <id> <option(e.g. description, nx, timeout)> <value...>\n" +
+			"/set <id> <object list option(e.g. displayorder)> <id> [<id> ...] [/addfirst | /addlast | /remove]\n" +
+			"    booleans: on/off, yes/no; integers: number or name (e.g. nx OptIn, bootmenupolicy Legacy); objects: {id} [{id} ...]\n" +
+			"    devices: partition=gpt:{disk}:{partition}, partition=mbr:<signature>:<offset>, boot, locate, ramdisk=[boot]\\sources\\boot.wim,{options}\n" +
 			"This command sets an entry option value in the boot configuration data store.",
 		Writable: 1,
 		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
 			setFlagset := flag.NewFlagSet("", flag.ExitOnError)
 			setFlagset.StringVar(&flags.SetValueType, "type", "", "")
+			setFlagset.StringVar(&flags.SetValueType, "value-type", "", "")
 			setFlagset.StringVar(&flags.SetValueRaw, "raw", "", "")
+			setFlagset.StringVar(&flags.SetValueRaw, "value-raw", "", "")
 			setFlagset.Var(&flags.SetValue, "value", "")
 
-			if strings.Contains(args[0], "help") || strings.Contains(args[0], "?") {
+			if len(args) > 0 && (strings.Contains(args[0], "help") || strings.Contains(args[0], "?")) {
 				setFlagset.PrintDefaults()
 				return nil
 			}
+			if len(args) < 2 {
+				return errors.New("need /set <id> <element> ...")
+			}
 
 			flags.SetId = args[0]
 			flags.SetKey = args[1]
+			if len(args) > 2 && !strings.HasPrefix(args[2], "-") {
+				var err error
+				flags.SetValue, flags.ListOperation, err = ParseListArgs(args[2:])
+				if err != nil {
+					return err
+				}
+				return doSet(flags, bcd)
+			}
 			setFlagset.Parse(args[2:])
 			return doSetRaw(flags, bcd)
 		},
 	},
+
+	// bcdedit /store BCD /delete {ObjectId} /cleanup
+	"delete": {
+		Usage: "/delete <id> [/cleanup]\n" +
+			"This command deletes an entry from the boot configuration data store.\n" +
+			"/cleanup also removes the identifier from the object lists of {bootmgr} and {fwbootmgr}.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) < 1 {
+				return errors.New("need /delete <id>")
+			}
+			flags.DeleteId = args[0]
+
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.BoolVar(&flags.DeleteCleanup, "cleanup", false, "")
+			subFlagset.Parse(args[1:])
+
+			return doDeleteObject(flags, bcd)
+		},
+	},
+
+	// bcdedit /store BCD /deletevalue {ObjectId} 12000004
+	// bcdedit /store BCD /deletevalue {ObjectId} Description
+	"deletevalue": {
+		Usage: "/deletevalue <id> <element(e.g. 12000004 or Description)>\n" +
+			"This command deletes a specified element from a boot entry.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) != 2 {
+				return errors.New("need /deletevalue <id> <element>")
+			}
+			flags.DeleteValueId = args[0]
+			flags.DeleteValueKey = args[1]
+			return doDeleteValue(flags, bcd)
+		},
+	},
+
+	// bcdedit /store BCD /copy {current} /d "Debug"
+	"copy": {
+		Usage: "/copy <id> /d <description>\n" +
+			"This command makes a copy of a specified boot entry and prints the new identifier.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) < 1 {
+				return errors.New("need /copy <id> /d <description>")
+			}
+			flags.CopyId = args[0]
+
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.StringVar(&flags.ObjectDescription, "d", "", "description")
+			subFlagset.Parse(args[1:])
+
+			return doCopyObject(flags, bcd)
+		},
+	},
+
+	// bcdedit /store BCD /export C:\Data\BCDBackup
+	"export": {
+		Usage: "/export <file>\n" +
+			"This command exports the contents of the store to a file, which can be used later to restore the store.",
+		Writable: 0,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) != 1 {
+				return errors.New("need /export <file>")
+			}
+			return go_bcdedit.ExportStore(bcd, args[0])
+		},
+	},
+
+	// bcdedit /store BCD /import C:\Data\BCDBackup /clean
+	"import": {
+		Usage: "/import <file> [/clean]\n" +
+			"This command restores the store from a file previously generated with /export.\n" +
+			"Existing entries are deleted, except firmware boot entries unless /clean is given.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) < 1 {
+				return errors.New("need /import <file>")
+			}
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.BoolVar(&flags.ImportClean, "clean", false, "also delete firmware boot entries")
+			subFlagset.Parse(args[1:])
+
+			src, err := go_bcdedit.OpenStoreWithLock(args[0], false, flags.LockOptions())
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			return go_bcdedit.ImportStore(bcd, src, flags.ImportClean)
+		},
+	},
+
+	// bcdedit /store BCD /import-json snapshot.json
+	"import-json": {
+		Usage: "/import-json <file> [/clean]\n" +
+			"This command restores the store from the output of /enum all --json, like /import.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) < 1 {
+				return errors.New("need /import-json <file>")
+			}
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.BoolVar(&flags.ImportClean, "clean", false, "also delete firmware boot entries")
+			subFlagset.Parse(args[1:])
+
+			raw, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var response model.EnumerateResponse
+			if err = json.Unmarshal(raw, &response); err != nil {
+				return errors.Wrap(err, args[0])
+			}
+			return go_bcdedit.ImportJson(bcd, &response, flags.ImportClean)
+		},
+	},
+
+	// bcdedit /store BCD /apply desired.yaml /prune
+	"apply": {
+		Usage: "/apply <file> [/prune]\n" +
+			"This command converges the store to a YAML or JSON spec of object ids mapped to option values,\n" +
+			"e.g. \"{bootmgr}\": {timeout: 5}. type (e.g. osloader) creates or retypes the entry and null deletes an option.\n" +
+			"/prune also deletes the options of listed objects that the spec does not mention.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) < 1 {
+				return errors.New("need /apply <file>")
+			}
+			subFlagset := flag.NewFlagSet("", flag.ExitOnError)
+			subFlagset.BoolVar(&flags.ApplyPrune, "prune", false, "delete options not in the spec")
+			subFlagset.Parse(args[1:])
+
+			return doApply(flags, args[0], bcd)
+		},
+	},
+
+	// bcdedit /store BCD /diff BCD.new
+	"diff": {
+		Usage: "/diff <other_store>\n" +
+			"This command lists the changes that turn the store into another store.\n" +
+			"With --json the output is a patch that /patch applies.",
+		Writable: 0,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) != 1 {
+				return errors.New("need /diff <other_store>")
+			}
+			other, err := go_bcdedit.OpenStoreWithLock(args[0], false, flags.LockOptions())
+			if err != nil {
+				return err
+			}
+			defer other.Close()
+			return doDiff(flags, bcd, other)
+		},
+	},
+
+	// bcdedit /store BCD /patch changes.json
+	"patch": {
+		Usage: "/patch <file>\n" +
+			"This command applies a patch written by /diff --json. It fails without changes if the store\n" +
+			"does not hold the old values recorded in the patch.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) != 1 {
+				return errors.New("need /patch <file>")
+			}
+			raw, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var patch model.Patch
+			if err = json.Unmarshal(raw, &patch); err != nil {
+				return errors.Wrap(err, args[0])
+			}
+			return go_bcdedit.ApplyPatch(bcd, &patch)
+		},
+	},
+
+	"references": {
+		Usage: "/references <id>\n" +
+			"This command lists the entries and settings that refer to an entry, and the entries it refers to.",
+		Writable: 0,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			if len(args) != 1 {
+				return errors.New("need /references <id>")
+			}
+			return doReferences(flags, args[0], bcd)
+		},
+	},
+
+	// bcdedit /store BCD /displayorder {ObjectId} /addlast
+	"displayorder": {
+		Usage: "/displayorder <id> [<id> ...] [/addfirst | /addlast | /remove] [/firmware]\n" +
+			"This command sets the display order that the boot manager uses when displaying the boot menu.\n" +
+			"/firmware edits {fwbootmgr} instead of {bootmgr}.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			return doBootManagerList(flags, args, bcd, model.ElementDisplayOrder)
+		},
+	},
+
+	// bcdedit /store BCD /bootsequence {ObjectId}
+	"bootsequence": {
+		Usage: "/bootsequence <id> [<id> ...] [/addfirst | /addlast | /remove] [/firmware]\n" +
+			"This command sets a one-time boot sequence to be used by the boot manager.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			return doBootManagerList(flags, args, bcd, model.ElementBootSequence)
+		},
+	},
+
+	// bcdedit /store BCD /toolsdisplayorder {memdiag}
+	"toolsdisplayorder": {
+		Usage: "/toolsdisplayorder <id> [<id> ...] [/addfirst | /addlast | /remove] [/firmware]\n" +
+			"This command sets the order in which the boot manager displays the tools menu.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			return doBootManagerList(flags, args, bcd, model.ElementToolsDisplayOrder)
+		},
+	},
+
+	// bcdedit /store BCD /default {ObjectId}
+	"default": {
+		Usage: "/default <id> [/firmware]\n" +
+			"This command sets the default entry that the boot manager will use.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			ids, err := parseBootManagerArgs(flags, args)
+			if err != nil {
+				return err
+			}
+			if len(ids) != 1 || flags.ListOperation != go_bcdedit.ListReplace {
+				return errors.New("/default takes a single identifier")
+			}
+			manager, err := bcd.GetObject(flags.BootManagerId())
+			if err != nil {
+				return err
+			}
+			id, err := go_bcdedit.ResolveExistingObjectId(bcd, ids[0])
+			if err != nil {
+				return err
+			}
+			_, err = manager.SetObject(model.ElementDefaultObject, id)
+			return err
+		},
+	},
+
+	// bcdedit /store BCD /timeout 30
+	"timeout": {
+		Usage: "/timeout <timeout> [/firmware]\n" +
+			"This command sets the time to wait, in seconds, before the boot manager selects the default entry.",
+		Writable: 1,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			values, err := parseBootManagerArgs(flags, args)
+			if err != nil {
+				return err
+			}
+			if len(values) != 1 || flags.ListOperation != go_bcdedit.ListReplace {
+				return errors.New("/timeout takes a single number of seconds")
+			}
+			timeout, err := strconv.ParseUint(values[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid timeout: %s", values[0])
+			}
+			manager, err := bcd.GetObject(flags.BootManagerId())
+			if err != nil {
+				return err
+			}
+			_, err = manager.SetInteger(model.ElementTimeout, timeout)
+			return err
+		},
+	},
+
+	// bcdedit /store BCD /validate
+	"validate": {
+		Usage: "/validate\n" +
+			"This command checks the store for dangling references, inheritance cycles, mistyped, misplaced,\n" +
+			"missing or unknown elements.",
+		Writable: 0,
+		Runner: func(flags *Flags, args []string, bcd go_bcdedit.Bcdedit) error {
+			return doValidate(flags, bcd)
+		},
+	},
 }
 
 func Main(args []string) {
 	var flags Flags
-	var err error
 
+	command, runnerArgs := parseArgs(args, &flags)
+	err := func() error {
+		define, ok := commands[command]
+		if !ok {
+			return errors.New("no command")
+		}
+		var err error
+		var bcd go_bcdedit.Bcdedit
+		if define.Writable >= 0 && flags.Store != "" {
+			bcd, err = go_bcdedit.OpenStoreWithLock(flags.Store, define.Writable == 1, flags.LockOptions())
+		}
+		if err != nil {
+			return err
+		}
+		if hiveBcd, ok := bcd.(*go_bcdedit.HiveBcdedit); ok {
+			hiveBcd.Aliases, err = flags.AliasResolver()
+			if err != nil {
+				bcd.Close()
+				return err
+			}
+		}
+		if bcd != nil && define.Writable == 1 {
+			if err = bcd.Begin(); err != nil {
+				bcd.Close()
+				return err
+			}
+		}
+		runErr := define.Runner(&flags, runnerArgs, bcd)
+		if bcd == nil {
+			return runErr
+		}
+		// a failed command leaves the store as it was
+		if runErr == nil && define.Writable == 1 {
+			runErr = bcd.Commit()
+		}
+		closeErr := bcd.Close()
+		if runErr != nil {
+			return runErr
+		}
+		return closeErr
+	}()
+
+	if err != nil {
+		log.Panicln(err)
+	} else {
+		log.Println("The operation completed successfully")
+	}
+}
+
+// parseArgs parses the global flags into flags and returns the command and
+// its arguments. Global flags such as /json may also follow the command.
+func parseArgs(args []string, flags *Flags) (string, []string) {
 	flagset := flag.NewFlagSet(args[0], flag.ExitOnError)
 	flagset.BoolVar(&flags.Json, "json", false, "Output result as JSON")
 	flagset.StringVar(&flags.Store, "store", "", "Used to specify a BCD store.")
+	flagset.StringVar(&flags.Aliases, "aliases", "", "Alias config file (default <user config dir>/go-bcdedit/aliases.json if present).")
+	flagset.StringVar(&flags.Current, "current", "", "The entry {current} refers to, e.g. {default}.")
+	flagset.DurationVar(&flags.LockTimeout, "lock-timeout", 0, "Wait up to this long (e.g. 10s) for a store locked by another process.")
 
 	appliedCommand := make(map[string]*bool)
 	for s, def := range commands {
@@ -130,47 +566,55 @@ func Main(args []string) {
 
 	var fixedArgs []string
 	for _, s := range args[1:] {
-		if strings.HasPrefix(s, "/") {
+		// "/enum" is an option, "/tmp/BCD" is a path
+		if strings.HasPrefix(s, "/") && !strings.Contains(s[1:], "/") {
 			s = "--" + s[1:]
 		}
 		fixedArgs = append(fixedArgs, s)
 	}
-	flagset.Parse(fixedArgs)
 
-	err = func() error {
-		for s, define := range commands {
-			if *appliedCommand[s] {
-				var err error
-				var bcd go_bcdedit.Bcdedit
-				if define.Writable >= 0 && flags.Store != "" {
-					bcd, err = go_bcdedit.OpenStore(flags.Store, define.Writable == 1)
-				}
-				if err != nil {
-					return err
-				}
-				runErr := define.Runner(&flags, flagset.Args(), bcd)
-				if bcd == nil {
-					return runErr
-				}
-				closeErr := bcd.Close()
-				if runErr != nil {
-					return runErr
-				}
-				return closeErr
-			}
+	// everything after the command belongs to it, even flags like /d,
+	// except for the global flags
+	commandArgs := fixedArgs
+	var rest []string
+	for i, s := range fixedArgs {
+		if _, ok := commands[strings.TrimPrefix(s, "--")]; ok && strings.HasPrefix(s, "--") {
+			commandArgs = fixedArgs[:i+1]
+			rest = fixedArgs[i+1:]
+			break
 		}
-		return errors.New("no command")
-	}()
+	}
+	var globalArgs, runnerArgs []string
+	for i := 0; i < len(rest); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(rest[i], "--"), "=")
+		_, isCommand := commands[name]
+		f := flagset.Lookup(name)
+		if !strings.HasPrefix(rest[i], "--") || f == nil || isCommand {
+			runnerArgs = append(runnerArgs, rest[i])
+			continue
+		}
+		globalArgs = append(globalArgs, rest[i])
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		if !hasValue && !(ok && boolFlag.IsBoolFlag()) && i+1 < len(rest) {
+			i++
+			globalArgs = append(globalArgs, rest[i])
+		}
+	}
 
-	if err != nil {
-		log.Panicln(err)
-	} else {
-		log.Println("The operation completed successfully")
+	flagset.Parse(commandArgs)
+	runnerArgs = append(flagset.Args(), runnerArgs...)
+	flagset.Parse(globalArgs)
+
+	for s := range commands {
+		if *appliedCommand[s] {
+			return s, runnerArgs
+		}
 	}
+	return "", runnerArgs
 }
 
 func doCreateStore(flags *Flags) error {
-	bcd, err := go_bcdedit.CreateStore(flags.Store)
+	bcd, err := go_bcdedit.CreateStoreWithLock(flags.Store, flags.LockOptions())
 	if err != nil {
 		return err
 	}
@@ -178,7 +622,7 @@ func doCreateStore(flags *Flags) error {
 }
 
 func doEnum(flags *Flags, bcd go_bcdedit.Bcdedit) error {
-	objectMap, err := bcd.Enumerate(flags.Enum)
+	objectMap, err := go_bcdedit.EnumerateObjects(bcd, flags.Enum)
 	if err != nil {
 		return err
 	}
@@ -190,6 +634,19 @@ func doEnum(flags *Flags, bcd go_bcdedit.Bcdedit) error {
 
 		for id, object := range objectMap {
 			response.Objects[id] = object.ToJson()
+			if flags.EnumEffective {
+				effective, err := go_bcdedit.EffectiveElements(bcd, id)
+				if err != nil {
+					return err
+				}
+				elements := make(map[string]*model.BcdElement)
+				for key, element := range effective {
+					jsonElement := element.Element.(*go_bcdedit.HiveBcdElement).ToJson()
+					jsonElement.Source = element.Source
+					elements[key.String()] = jsonElement
+				}
+				response.Objects[id].Elements = elements
+			}
 		}
 
 		jsonResp, err := json.Marshal(response)
@@ -205,63 +662,52 @@ func doEnum(flags *Flags, bcd go_bcdedit.Bcdedit) error {
 		objectList = append(objectList, object)
 	}
 
-	slices.SortFunc(objectList, func(a, b go_bcdedit.BcdObject) int {
-		if a.GetDescription() < b.GetDescription() {
-			return -1
-		} else if a.GetDescription() > b.GetDescription() {
-			return 1
+	printer := newEnumPrinter(bcd, flags.EnumVerbose)
+	printer.sort(objectList)
+	for _, object := range objectList {
+		if err = printer.printObject(object, flags.EnumEffective); err != nil {
+			return err
 		}
-		return 0
-	})
+		fmt.Printf("\n")
+	}
 
-	for _, object := range objectList {
-		switch object.GetDescription().ObjectType() {
-		case model.ObjectApplication:
-			applicationTypeName := object.GetDescription().ApplicationType().String()
-			if applicationTypeName == "" {
-				applicationTypeName = fmt.Sprintf("unknown type (0x%08x)", object.GetDescription())
-			}
-			fmt.Printf("%s\n", applicationTypeName)
-		case model.ObjectInherit:
-			switch object.GetDescription().ObjectSubType() {
-			case model.InheritableByApplicationObjects:
-				fmt.Printf("%s (inherited)\n", object.GetDescription().ApplicationType().String())
-			case model.InheritableByDeviceObjects:
-				fmt.Printf("Device options (inherited)\n")
-			default:
-				fmt.Printf("Inherited\n")
-				fmt.Printf("object_description: 0x%08x\n", object.GetDescription())
-			}
-		case model.ObjectDevice:
-			fmt.Printf("%s\n", "Device options")
-		default:
-			fmt.Printf("OBJECT[ID: %s]:\n", object.GetId())
-			fmt.Printf("object_description: 0x%08x\n", object.GetDescription())
-		}
-		fmt.Printf("%s\n", strings.Repeat("-", 25))
-		fmt.Printf("%s %s\n", StringWithPad("Identifier"), ObjectIdToString(object.GetId()))
-
-		for _, element := range object.SortedElements() {
-			hiveElement := element.(*go_bcdedit.HiveBcdElement)
-			meta := hiveElement.Meta()
-			name := hiveElement.Key
-			if meta != nil {
-				name = meta.Name
-			}
-			if name == "Inherit" || name == "DisplayOrder" {
-				var fixedIds []string
-				for _, parentId := range strings.Split(hiveElement.String(), "\n") {
-					fixedIds = append(fixedIds, ObjectIdToString(parentId))
-				}
-				fmt.Printf("%s %s\n", StringWithPad(name), StringWithNewLinePad(strings.Join(fixedIds, "\n")))
-			} else {
-				fmt.Printf("%s %s\n", StringWithPad(name), StringWithNewLinePad(hiveElement.String()))
+	return nil
+}
+
+func doValidate(flags *Flags, bcd go_bcdedit.Bcdedit) error {
+	issues, err := bcd.Validate()
+	if err != nil {
+		return err
+	}
+
+	if flags.Json {
+		response := &model.ValidateResponse{
+			Issues: issues,
+		}
+		if response.Issues == nil {
+			response.Issues = []*model.ValidationIssue{}
+		}
+		jsonResp, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(jsonResp)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			target := ObjectIdToString(issue.ObjectId)
+			if issue.Element != "" {
+				target += " " + issue.Element
 			}
+			fmt.Printf("%s %s: %s\n", StringWithPad(string(issue.Kind)), target, issue.Message)
 		}
-		fmt.Printf("\n")
-		_ = object
 	}
 
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issue(s) found", len(issues))
+	}
 	return nil
 }
 
@@ -270,42 +716,348 @@ func doCreateObject(flags *Flags, bcd go_bcdedit.Bcdedit) error {
 		return errors.New("need object-type")
 	}
 
+	generated := flags.CreateId == ""
+	if generated {
+		id, err := model.NewObjectId()
+		if err != nil {
+			return err
+		}
+		flags.CreateId = id.String()
+	}
+
 	object, err := bcd.UpsertObject(flags.CreateId, model.BcdDescription(flags.CreateDescription))
 	if err != nil {
 		return err
 	}
 
 	if len(flags.ObjectDescription) > 0 {
-		raw, err := go_bcdedit.StringToUtf16LE(flags.ObjectDescription)
+		_, err = object.SetString(model.ElementDescription, flags.ObjectDescription)
+		if err != nil {
+			return err
+		}
+	}
+	if generated {
+		fmt.Printf("The entry %s was successfully created.\n", object.GetId())
+	}
+	return nil
+}
+
+// ParseListArgs splits /addfirst, /addlast and /remove off the values.
+func ParseListArgs(args []string) ([]string, go_bcdedit.ListOperation, error) {
+	var values []string
+	op := go_bcdedit.ListReplace
+	for _, arg := range args {
+		next := op
+		switch arg {
+		case "--addfirst":
+			next = go_bcdedit.ListAddFirst
+		case "--addlast":
+			next = go_bcdedit.ListAddLast
+		case "--remove":
+			next = go_bcdedit.ListRemove
+		default:
+			values = append(values, arg)
+			continue
+		}
+		if op != go_bcdedit.ListReplace {
+			return nil, op, errors.New("only one of /addfirst, /addlast and /remove can be used")
+		}
+		op = next
+	}
+	return values, op, nil
+}
+
+// parseBootManagerArgs handles /firmware and the list operations of the boot
+// manager commands and returns the remaining values.
+func parseBootManagerArgs(flags *Flags, args []string) ([]string, error) {
+	var rest []string
+	for _, arg := range args {
+		if arg == "--firmware" {
+			flags.Firmware = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	values, op, err := ParseListArgs(rest)
+	if err != nil {
+		return nil, err
+	}
+	flags.ListOperation = op
+	return values, nil
+}
+
+func (f *Flags) BootManagerId() string {
+	if f.Firmware {
+		return go_bcdedit.FwBootMgrObjectId
+	}
+	return go_bcdedit.BootMgrObjectId
+}
+
+func doBootManagerList(flags *Flags, args []string, bcd go_bcdedit.Bcdedit, key model.ElementType) error {
+	ids, err := parseBootManagerArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	manager, err := bcd.GetObject(flags.BootManagerId())
+	if err != nil {
+		return err
+	}
+	return go_bcdedit.UpdateObjectList(bcd, manager, key, ids, flags.ListOperation)
+}
+
+func doApply(flags *Flags, file string, bcd go_bcdedit.Bcdedit) error {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	spec, err := go_bcdedit.ParseApplySpec(raw)
+	if err != nil {
+		return errors.Wrap(err, file)
+	}
+	response, err := go_bcdedit.Apply(bcd, spec, go_bcdedit.ApplyOptions{
+		Prune: flags.ApplyPrune,
+	})
+	if err != nil {
+		return err
+	}
+
+	if flags.Json {
+		jsonResp, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(jsonResp)
+		return err
+	}
+	for _, action := range response.Actions {
+		line := StringWithPad(string(action.Kind)) + ObjectIdToString(action.ObjectId)
+		if action.Name != "" {
+			line += " " + action.Name
+		} else if action.Element != "" {
+			line += " " + action.Element
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("changed: %t\n", response.Changed)
+	return nil
+}
+
+func doDiff(flags *Flags, bcd go_bcdedit.Bcdedit, other go_bcdedit.Bcdedit) error {
+	patch, err := go_bcdedit.Diff(bcd, other)
+	if err != nil {
+		return err
+	}
+
+	if flags.Json {
+		jsonResp, err := json.Marshal(patch)
 		if err != nil {
 			return err
 		}
-		_, err = object.SetElement("12000004", go_bcdedit.RegSz, raw)
+		_, err = os.Stdout.Write(jsonResp)
 		return err
 	}
+	for _, operation := range patch.Operations {
+		id := ObjectIdToString(operation.ObjectId)
+		name := operation.Name
+		if name == "" {
+			name = operation.Element
+		}
+		oldValue := strings.ReplaceAll(operation.OldValue, "\n", " ")
+		newValue := strings.ReplaceAll(operation.NewValue, "\n", " ")
+		switch operation.Op {
+		case model.PatchAddObject:
+			fmt.Printf("+ %s (%08x)\n", id, uint32(*operation.Description))
+		case model.PatchRemoveObject:
+			fmt.Printf("- %s (%08x)\n", id, uint32(*operation.OldDescription))
+		case model.PatchSetType:
+			fmt.Printf("~ %s type %08x -> %08x\n", id, uint32(*operation.OldDescription), uint32(*operation.Description))
+		case model.PatchAddElement:
+			fmt.Printf("+ %s %s: %s\n", id, name, newValue)
+		case model.PatchRemoveElement:
+			fmt.Printf("- %s %s: %s\n", id, name, oldValue)
+		case model.PatchReplaceElement:
+			fmt.Printf("~ %s %s: %s -> %s\n", id, name, oldValue, newValue)
+		}
+	}
 	return nil
 }
 
+func doReferences(flags *Flags, id string, bcd go_bcdedit.Bcdedit) error {
+	id, err := go_bcdedit.ResolveExistingObjectId(bcd, id)
+	if err != nil {
+		return err
+	}
+	index, err := go_bcdedit.NewReferenceIndex(bcd)
+	if err != nil {
+		return err
+	}
+	response := &model.ReferencesResponse{
+		ObjectId: id,
+		Inbound:  index.Inbound(id),
+		Outbound: index.Outbound(id),
+	}
+
+	if flags.Json {
+		if response.Inbound == nil {
+			response.Inbound = []*model.Reference{}
+		}
+		if response.Outbound == nil {
+			response.Outbound = []*model.Reference{}
+		}
+		jsonResp, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(jsonResp)
+		return err
+	}
+
+	printer := newEnumPrinter(bcd, false)
+	name := func(reference *model.Reference) string {
+		if reference.Name != "" {
+			return reference.Name
+		}
+		return "custom:" + reference.Element
+	}
+	fmt.Println("Referenced by")
+	fmt.Println(strings.Repeat("-", 13))
+	for _, reference := range response.Inbound {
+		fmt.Printf("%s %s\n", StringWithPad(printer.objectId(reference.From)), name(reference))
+	}
+	fmt.Println()
+	fmt.Println("References")
+	fmt.Println(strings.Repeat("-", 10))
+	for _, reference := range response.Outbound {
+		target := printer.objectId(reference.To)
+		if reference.Dangling {
+			target += " (missing)"
+		}
+		fmt.Printf("%s %s\n", StringWithPad(name(reference)), target)
+	}
+	return nil
+}
+
+func doCopyObject(flags *Flags, bcd go_bcdedit.Bcdedit) error {
+	if flags.ObjectDescription == "" {
+		return errors.New("need /d <description>")
+	}
+	object, err := bcd.CopyObject(flags.CopyId, "", flags.ObjectDescription)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("The entry was successfully copied to %s.\n", object.GetId())
+	return nil
+}
+
+func doSet(flags *Flags, bcd go_bcdedit.Bcdedit) error {
+	object, err := bcd.GetObject(flags.SetId)
+	if err != nil {
+		return err
+	}
+	key, err := go_bcdedit.ResolveOption(object, flags.SetKey)
+	if err != nil {
+		return err
+	}
+	if flags.ListOperation != go_bcdedit.ListReplace {
+		return go_bcdedit.UpdateObjectList(bcd, object, key, flags.SetValue, flags.ListOperation)
+	}
+	_, err = go_bcdedit.SetOptionValue(bcd, object, key, flags.SetValue)
+	return err
+}
+
 func doSetRaw(flags *Flags, bcd go_bcdedit.Bcdedit) error {
-	var err error
+	object, err := bcd.GetObject(flags.SetId)
+	if err != nil {
+		return err
+	}
+	key, err := go_bcdedit.ResolveOption(object, flags.SetKey)
+	if err != nil {
+		return err
+	}
+
+	// the registry value type follows from the element format
+	valueType := model.ValueType(flags.SetValueType)
+	expected := key.Format().ValueType()
+	if valueType == "" {
+		if expected == "" {
+			return fmt.Errorf("element %s has unknown format, needs --value-type", key)
+		}
+		valueType = expected
+	} else if expected != "" && valueType != expected {
+		return fmt.Errorf("element %s has %s format which is stored as %s, not %s", key, key.Format(), expected, valueType)
+	}
+
 	var raw []byte
 	if flags.SetValueRaw != "" {
 		raw, err = base64.StdEncoding.DecodeString(flags.SetValueRaw)
 	} else {
-		raw, err = DecodeValueToRaw(model.ValueType(flags.SetValueType), flags.SetValue)
+		raw, err = DecodeValueToRaw(valueType, flags.SetValue)
 	}
 	if err != nil {
 		return err
 	}
 
-	object, err := bcd.GetObject(flags.SetId)
+	_, err = object.SetElement(key, go_bcdedit.ValueTypeFromJson(valueType), raw)
+	return err
+}
+
+func doDeleteObject(flags *Flags, bcd go_bcdedit.Bcdedit) error {
+	deleteId, err := bcd.ResolveObjectId(flags.DeleteId)
+	if err != nil {
+		return err
+	}
+	err = bcd.DeleteObject(deleteId)
 	if err != nil {
 		return err
 	}
+	if !flags.DeleteCleanup {
+		return nil
+	}
 
-	valueType := go_bcdedit.ValueTypeFromJson(model.ValueType(flags.SetValueType))
-	_, err = object.SetElement(flags.SetKey, valueType, raw)
-	return err
+	for _, managerId := range []string{go_bcdedit.BootMgrObjectId, go_bcdedit.FwBootMgrObjectId} {
+		if strings.EqualFold(managerId, deleteId) {
+			continue
+		}
+		manager, err := bcd.GetObject(managerId)
+		if errors.Is(err, go_bcdedit.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for key, element := range manager.GetElements() {
+			if key.Format() != model.ElementFormatObjectList {
+				continue
+			}
+			ids, err := element.GetObjectList()
+			if err != nil {
+				return err
+			}
+			remaining := slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+				return strings.EqualFold(id, deleteId)
+			})
+			if len(remaining) == len(ids) {
+				continue
+			}
+			_, err = manager.SetObjectList(key, remaining)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func doDeleteValue(flags *Flags, bcd go_bcdedit.Bcdedit) error {
+	object, err := bcd.GetObject(flags.DeleteValueId)
+	if err != nil {
+		return err
+	}
+	key, err := go_bcdedit.ResolveOption(object, flags.DeleteValueKey)
+	if err != nil {
+		return err
+	}
+	return object.DeleteElement(key)
 }
 
 func ObjectIdToString(id string) string {
@@ -316,8 +1068,13 @@ func ObjectIdToString(id string) string {
 	return id
 }
 
+// StringWithPad pads s so that a value printed after it and a space starts
+// at column 24, as in bcdedit output.
 func StringWithPad(s string) string {
-	pad := 24
+	pad := 23
+	if len(s) >= pad {
+		return s
+	}
 	return s + strings.Repeat(" ", pad-len(s))
 }
 
@@ -327,7 +1084,7 @@ func StringWithNewLinePad(s string) string {
 		if i == 0 {
 			out = v
 		} else {
-			out += "\n" + strings.Repeat(" ", 25) + v
+			out += "\n" + strings.Repeat(" ", 24) + v
 		}
 	}
 	return out
@@ -338,6 +1095,12 @@ func StringWithNewLinePad(s string) string {
 func DecodeValueToRaw(valueType model.ValueType, input []string) ([]byte, error) {
 	var err error
 	switch valueType {
+	case model.RegSz, model.RegBinary, model.RegDword, model.RegQword:
+		if len(input) == 0 {
+			return nil, fmt.Errorf("%s needs a value", valueType)
+		}
+	}
+	switch valueType {
 	case model.RegNone:
 		return []byte{}, nil
 	case model.RegSz:
@@ -0,0 +1,190 @@
+package bcdedit_cmd
+
+import (
+	go_bcdedit "github.com/jc-lab/go-bcdedit"
+	"github.com/jc-lab/go-bcdedit/model"
+	"github.com/jc-lab/go-bcdedit/pkg/hiveutil"
+	"slices"
+	"testing"
+)
+
+const (
+	testLoaderId = "{11111111-2222-4333-8444-555555555555}"
+	testOtherId  = "{66666666-7777-4888-9999-aaaaaaaaaaaa}"
+)
+
+var osloaderType = model.BcdDescriptionFrom(model.ObjectApplication, model.WindowsBootApplication, model.ApplicationOsloader)
+
+// newTestStore returns a memory store whose {bootmgr} displays two Windows
+// boot loaders, the first of them being the default.
+func newTestStore(t *testing.T) go_bcdedit.Bcdedit {
+	t.Helper()
+	bcd, err := go_bcdedit.CreateMemoryStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := bcd.UpsertObject("{bootmgr}", model.BcdDescriptionFrom(model.ObjectApplication, model.WindowsBootApplication, model.ApplicationBootmgr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{testLoaderId, testOtherId} {
+		loader, err := bcd.UpsertObject(id, osloaderType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = loader.SetString(model.ElementDescription, []string{"Windows", "Other"}[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = manager.SetObjectList(model.ElementDisplayOrder, []string{testLoaderId, testOtherId}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.SetObject(model.ElementDefaultObject, testLoaderId); err != nil {
+		t.Fatal(err)
+	}
+	return bcd
+}
+
+func TestParseArgsGlobalFlagsAfterCommand(t *testing.T) {
+	tests := []struct {
+		args       []string
+		command    string
+		runnerArgs []string
+		json       bool
+		store      string
+	}{
+		{[]string{"bcdedit", "/store", "/tmp/BCD", "/enum", "all", "/json"}, "enum", []string{"all"}, true, "/tmp/BCD"},
+		{[]string{"bcdedit", "--json", "/store", "/tmp/BCD", "/enum", "all"}, "enum", []string{"all"}, true, "/tmp/BCD"},
+		{[]string{"bcdedit", "/enum", "osloader", "/v", "/store", "/tmp/BCD"}, "enum", []string{"osloader", "--v"}, false, "/tmp/BCD"},
+		{[]string{"bcdedit", "--store=/tmp/BCD", "/copy", "{default}", "/d", "copy"}, "copy", []string{"{default}", "--d", "copy"}, false, "/tmp/BCD"},
+	}
+	for _, test := range tests {
+		var flags Flags
+		command, runnerArgs := parseArgs(test.args, &flags)
+		if command != test.command || !slices.Equal(runnerArgs, test.runnerArgs) {
+			t.Errorf("%v: got %s %q, want %s %q", test.args, command, runnerArgs, test.command, test.runnerArgs)
+		}
+		if flags.Json != test.json || flags.Store != test.store {
+			t.Errorf("%v: got json=%t store=%s", test.args, flags.Json, flags.Store)
+		}
+	}
+}
+
+func TestDecodeValueToRawWithoutValue(t *testing.T) {
+	for _, valueType := range []model.ValueType{model.RegSz, model.RegBinary, model.RegDword, model.RegQword} {
+		if _, err := DecodeValueToRaw(valueType, nil); err == nil {
+			t.Errorf("%s without a value: expected an error", valueType)
+		}
+	}
+}
+
+func TestDeleteObjectCleanup(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	err := doDeleteObject(&Flags{DeleteId: testLoaderId, DeleteCleanup: true}, bcd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := bcd.GetObject("{bootmgr}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := manager.GetElements()[model.ElementDisplayOrder].GetObjectList()
+	if err != nil || !slices.Equal(ids, []string{testOtherId}) {
+		t.Errorf("display order %v, %v", ids, err)
+	}
+}
+
+func TestDeleteObjectCleanupCorruptManager(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	// {fwbootmgr} without its Description key cannot be read
+	h := bcd.(*go_bcdedit.HiveBcdedit).Hive
+	objects, err := hiveutil.GetObjectsNode(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.NodeAddChild(objects, go_bcdedit.FwBootMgrObjectId); err != nil {
+		t.Fatal(err)
+	}
+
+	err = doDeleteObject(&Flags{DeleteId: testLoaderId, DeleteCleanup: true}, bcd)
+	if err == nil {
+		t.Error("cleanup with an unreadable {fwbootmgr}: expected an error")
+	}
+}
+
+func TestDeleteValue(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	for _, name := range []string{"description", "12000004"} {
+		object, err := bcd.GetObject(testLoaderId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = object.SetString(model.ElementDescription, "Windows"); err != nil {
+			t.Fatal(err)
+		}
+		if err = doDeleteValue(&Flags{DeleteValueId: testLoaderId, DeleteValueKey: name}, bcd); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		object, _ = bcd.GetObject(testLoaderId)
+		if _, ok := object.GetElements()[model.ElementDescription]; ok {
+			t.Errorf("%s: element not deleted", name)
+		}
+	}
+}
+
+func TestBootManagerCommands(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	run := func(command string, args ...string) error {
+		return commands[command].Runner(&Flags{}, args, bcd)
+	}
+	for _, test := range [][]string{
+		{"default", testOtherId},
+		{"timeout", "5"},
+		{"bootsequence", testOtherId},
+		{"bootsequence", testLoaderId, "--addlast"},
+		{"displayorder", testLoaderId, "--remove"},
+	} {
+		if err := run(test[0], test[1:]...); err != nil {
+			t.Fatalf("%v: %v", test, err)
+		}
+	}
+
+	manager, err := bcd.GetObject("{bootmgr}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements := manager.GetElements()
+	if id, err := elements[model.ElementDefaultObject].GetObject(); err != nil || id != testOtherId {
+		t.Errorf("default %s, %v", id, err)
+	}
+	if timeout, err := elements[model.ElementTimeout].GetInteger(); err != nil || timeout != 5 {
+		t.Errorf("timeout %d, %v", timeout, err)
+	}
+	if ids, err := elements[model.ElementBootSequence].GetObjectList(); err != nil || !slices.Equal(ids, []string{testOtherId, testLoaderId}) {
+		t.Errorf("bootsequence %v, %v", ids, err)
+	}
+	if ids, err := elements[model.ElementDisplayOrder].GetObjectList(); err != nil || !slices.Equal(ids, []string{testOtherId}) {
+		t.Errorf("displayorder %v, %v", ids, err)
+	}
+
+	for _, test := range [][]string{
+		{"default", testLoaderId, testOtherId},
+		{"default", "{6e6e6e6e-0000-4000-8000-000000000000}"},
+		{"timeout", "-1"},
+		{"timeout", "5", "--addlast"},
+		{"displayorder", testLoaderId, "--addfirst", "--remove"},
+		{"displayorder", testLoaderId, "--firmware"},
+	} {
+		if err := run(test[0], test[1:]...); err == nil {
+			t.Errorf("%v: expected an error", test)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package hive defines the registry hive backend consumed by go-bcdedit.
+package hive
+
+const (
+	RegNone                     = 0
+	RegSz                       = 1
+	RegExpandSz                 = 2
+	RegBinary                   = 3
+	RegDword                    = 4
+	RegDwordBigEndian           = 5
+	RegLink                     = 6
+	RegMultiSz                  = 7
+	RegResourceList             = 8
+	RegFullResourceDescriptor   = 9
+	RegResourceRequirementsList = 10
+	RegQword                    = 11
+)
+
+type Value struct {
+	Type  int
+	Key   string
+	Value []byte
+}
+
+// Hive is a registry hive whose nodes and values are addressed by non-zero
+// handles. A zero handle is never valid.
+type Hive interface {
+	Root() (int64, error)
+	NodeName(node int64) (string, error)
+	NodeChildren(node int64) ([]int64, error)
+	NodeValues(node int64) ([]int64, error)
+	NodeValueKey(value int64) (string, error)
+	ValueValue(value int64) (valType int64, valueBytes []byte, err error)
+	NodeAddChild(parent int64, name string) (int64, error)
+	NodeSetValue(node int64, value Value) (int, error)
+	NodeDeleteChild(node int64) (int, error)
+	Commit() (int, error)
+	Close() error
+}
+
+// Snapshot is an opaque copy of a hive's in-memory state.
+type Snapshot interface{}
+
+// Snapshotter is implemented by hives that can save their in-memory state and
+// later return to it. Handles that were valid when the snapshot was taken stay
+// valid after Restore, and handles created in between are never reused.
+type Snapshotter interface {
+	Snapshot() (Snapshot, error)
+	Restore(snapshot Snapshot) error
+}
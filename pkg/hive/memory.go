@@ -0,0 +1,228 @@
+package hive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidHandle = errors.New("invalid node or value handle")
+
+type memoryNode struct {
+	parent   *memoryNode
+	name     string
+	children []int64
+	values   []int64
+}
+
+type memoryValue struct {
+	node  int64
+	key   string
+	typ   int
+	value []byte
+}
+
+// Memory is a Hive kept entirely in memory. Commit is a no-op.
+type Memory struct {
+	root       int64
+	nextHandle int64
+	nodes      map[int64]*memoryNode
+	values     map[int64]*memoryValue
+}
+
+var _ Hive = (*Memory)(nil)
+
+func NewMemory(rootName string) *Memory {
+	m := &Memory{
+		nodes:  make(map[int64]*memoryNode),
+		values: make(map[int64]*memoryValue),
+	}
+	m.root = m.newHandle()
+	m.nodes[m.root] = &memoryNode{name: rootName}
+	return m
+}
+
+func (m *Memory) newHandle() int64 {
+	m.nextHandle++
+	return m.nextHandle
+}
+
+func (m *Memory) node(handle int64) (*memoryNode, error) {
+	n, ok := m.nodes[handle]
+	if !ok {
+		return nil, ErrInvalidHandle
+	}
+	return n, nil
+}
+
+func (m *Memory) Root() (int64, error) {
+	return m.root, nil
+}
+
+func (m *Memory) NodeName(node int64) (string, error) {
+	n, err := m.node(node)
+	if err != nil {
+		return "", err
+	}
+	return n.name, nil
+}
+
+func (m *Memory) NodeChildren(node int64) ([]int64, error) {
+	n, err := m.node(node)
+	if err != nil {
+		return nil, err
+	}
+	return append([]int64(nil), n.children...), nil
+}
+
+func (m *Memory) NodeValues(node int64) ([]int64, error) {
+	n, err := m.node(node)
+	if err != nil {
+		return nil, err
+	}
+	return append([]int64(nil), n.values...), nil
+}
+
+func (m *Memory) NodeValueKey(value int64) (string, error) {
+	v, ok := m.values[value]
+	if !ok {
+		return "", ErrInvalidHandle
+	}
+	return v.key, nil
+}
+
+func (m *Memory) ValueValue(value int64) (int64, []byte, error) {
+	v, ok := m.values[value]
+	if !ok {
+		return 0, nil, ErrInvalidHandle
+	}
+	return int64(v.typ), append([]byte(nil), v.value...), nil
+}
+
+func (m *Memory) NodeAddChild(parent int64, name string) (int64, error) {
+	p, err := m.node(parent)
+	if err != nil {
+		return 0, err
+	}
+	if name == "" || strings.Contains(name, "\\") {
+		return 0, fmt.Errorf("invalid key name: %q", name)
+	}
+	for _, child := range p.children {
+		if strings.EqualFold(m.nodes[child].name, name) {
+			return 0, fmt.Errorf("key already exists: %s", name)
+		}
+	}
+	handle := m.newHandle()
+	m.nodes[handle] = &memoryNode{parent: p, name: name}
+	p.children = append(p.children, handle)
+	return handle, nil
+}
+
+func (m *Memory) NodeSetValue(node int64, value Value) (int, error) {
+	n, err := m.node(node)
+	if err != nil {
+		return -1, err
+	}
+	raw := append([]byte(nil), value.Value...)
+	for _, handle := range n.values {
+		v := m.values[handle]
+		if strings.EqualFold(v.key, value.Key) {
+			v.typ = value.Type
+			v.value = raw
+			return 0, nil
+		}
+	}
+	handle := m.newHandle()
+	m.values[handle] = &memoryValue{
+		node:  node,
+		key:   value.Key,
+		typ:   value.Type,
+		value: raw,
+	}
+	n.values = append(n.values, handle)
+	return 0, nil
+}
+
+func (m *Memory) NodeDeleteChild(node int64) (int, error) {
+	n, err := m.node(node)
+	if err != nil {
+		return -1, err
+	}
+	if n.parent == nil {
+		return -1, errors.New("cannot delete the root key")
+	}
+	for i, child := range n.parent.children {
+		if child == node {
+			n.parent.children = append(n.parent.children[:i], n.parent.children[i+1:]...)
+			break
+		}
+	}
+	m.deleteNode(node)
+	return 0, nil
+}
+
+func (m *Memory) deleteNode(node int64) {
+	n := m.nodes[node]
+	for _, child := range n.children {
+		m.deleteNode(child)
+	}
+	for _, value := range n.values {
+		delete(m.values, value)
+	}
+	delete(m.nodes, node)
+}
+
+func (m *Memory) Commit() (int, error) {
+	return 0, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+var _ Snapshotter = (*Memory)(nil)
+
+func (m *Memory) Snapshot() (Snapshot, error) {
+	return m.clone(), nil
+}
+
+func (m *Memory) Restore(snapshot Snapshot) error {
+	saved, ok := snapshot.(*Memory)
+	if !ok {
+		return fmt.Errorf("not a memory hive snapshot: %T", snapshot)
+	}
+	nextHandle := max(m.nextHandle, saved.nextHandle)
+	*m = *saved.clone()
+	m.nextHandle = nextHandle
+	return nil
+}
+
+func (m *Memory) clone() *Memory {
+	c := &Memory{
+		root:       m.root,
+		nextHandle: m.nextHandle,
+		nodes:      make(map[int64]*memoryNode, len(m.nodes)),
+		values:     make(map[int64]*memoryValue, len(m.values)),
+	}
+	copies := make(map[*memoryNode]*memoryNode, len(m.nodes))
+	for handle, n := range m.nodes {
+		copied := &memoryNode{
+			name:     n.name,
+			children: append([]int64(nil), n.children...),
+			values:   append([]int64(nil), n.values...),
+		}
+		c.nodes[handle] = copied
+		copies[n] = copied
+	}
+	for handle, n := range m.nodes {
+		if n.parent != nil {
+			c.nodes[handle].parent = copies[n.parent]
+		}
+	}
+	for handle, v := range m.values {
+		copied := *v
+		copied.value = append([]byte(nil), v.value...)
+		c.values[handle] = &copied
+	}
+	return c
+}
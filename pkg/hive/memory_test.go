@@ -0,0 +1,87 @@
+package hive
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory("Root")
+	root, _ := m.Root()
+	objects, err := m.NodeAddChild(root, "Objects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.NodeAddChild(root, "OBJECTS"); err == nil {
+		t.Error("adding a key that differs only in case: expected an error")
+	}
+	if _, err = m.NodeSetValue(objects, Value{Type: RegDword, Key: "Type", Value: []byte{1, 0, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.NodeSetValue(objects, Value{Type: RegBinary, Key: "TYPE", Value: []byte{2}}); err != nil {
+		t.Fatal(err)
+	}
+	values, _ := m.NodeValues(objects)
+	if len(values) != 1 {
+		t.Fatalf("%d values, want the value to be replaced", len(values))
+	}
+	key, _ := m.NodeValueKey(values[0])
+	typ, raw, _ := m.ValueValue(values[0])
+	if key != "Type" || typ != RegBinary || !bytes.Equal(raw, []byte{2}) {
+		t.Errorf("value %s = %d %x", key, typ, raw)
+	}
+
+	child, _ := m.NodeAddChild(objects, "Child")
+	if _, err = m.NodeDeleteChild(objects); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.NodeName(child); !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("deleted subkey: got %v, want ErrInvalidHandle", err)
+	}
+	if _, _, err = m.ValueValue(values[0]); !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("value of a deleted key: got %v, want ErrInvalidHandle", err)
+	}
+	if _, err = m.NodeDeleteChild(root); err == nil {
+		t.Error("deleting the root key: expected an error")
+	}
+}
+
+func TestMemorySnapshot(t *testing.T) {
+	m := NewMemory("Root")
+	root, _ := m.Root()
+	kept, _ := m.NodeAddChild(root, "Kept")
+	snapshot, err := m.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	added, _ := m.NodeAddChild(root, "Added")
+	m.NodeSetValue(kept, Value{Type: RegSz, Key: "Changed", Value: []byte("x")})
+	if err = m.Restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := m.NodeName(kept); err != nil || name != "Kept" {
+		t.Errorf("handle taken before the snapshot: %q, %v", name, err)
+	}
+	if values, _ := m.NodeValues(kept); len(values) != 0 {
+		t.Errorf("value set after the snapshot survived the restore")
+	}
+	if _, err = m.NodeName(added); !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("key added after the snapshot: got %v, want ErrInvalidHandle", err)
+	}
+	again, _ := m.NodeAddChild(root, "Again")
+	if again == added {
+		t.Errorf("handle %d reused after restore", added)
+	}
+
+	// the snapshot is not affected by later changes either
+	m.NodeAddChild(root, "Later")
+	if err = m.Restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if children, _ := m.NodeChildren(root); len(children) != 1 {
+		t.Errorf("%d subkeys after restoring twice, want 1", len(children))
+	}
+}
@@ -2,22 +2,23 @@ package hiveutil
 
 import (
 	"errors"
-	"github.com/gabriel-samfira/go-hivex"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
 	"io/fs"
+	"strings"
 )
 
 var SkipAll = fs.SkipAll
 
 type ReadFunc = func(node int64, name string, err error) error
 
-func GetObjectsNode(hive *hivex.Hivex) (int64, error) {
+func GetObjectsNode(hive hive.Hive) (int64, error) {
 	var foundNode int64
 	root, err := hive.Root()
 	if err != nil {
 		return 0, err
 	}
 	err = ReadNode(hive, root, func(node int64, name string, err error) error {
-		if name == "Objects" {
+		if strings.EqualFold(name, "Objects") {
 			foundNode = node
 			return SkipAll
 		}
@@ -32,7 +33,7 @@ func GetObjectsNode(hive *hivex.Hivex) (int64, error) {
 	return foundNode, nil
 }
 
-func ReadNode(hive *hivex.Hivex, parentNode int64, fn ReadFunc) error {
+func ReadNode(hive hive.Hive, parentNode int64, fn ReadFunc) error {
 	children, err := hive.NodeChildren(parentNode)
 	if err != nil {
 		return err
@@ -50,13 +51,15 @@ func ReadNode(hive *hivex.Hivex, parentNode int64, fn ReadFunc) error {
 	return nil
 }
 
-func FindChild(hive *hivex.Hivex, parentNode int64, targetKey string) (int64, error) {
+// FindChild returns the child named targetKey, or 0 if there is none. Like
+// all lookups here it is case-insensitive, matching registry semantics.
+func FindChild(hive hive.Hive, parentNode int64, targetKey string) (int64, error) {
 	var targetNode int64
 	err := ReadNode(hive, parentNode, func(childNode int64, name string, err error) error {
 		if err != nil {
 			return err
 		}
-		if name == targetKey {
+		if strings.EqualFold(name, targetKey) {
 			targetNode = childNode
 		}
 		return nil
@@ -67,7 +70,7 @@ func FindChild(hive *hivex.Hivex, parentNode int64, targetKey string) (int64, er
 	return targetNode, nil
 }
 
-func FindValue(hive *hivex.Hivex, parentNode int64, targetKey string) (int64, error) {
+func FindValue(hive hive.Hive, parentNode int64, targetKey string) (int64, error) {
 	values, err := hive.NodeValues(parentNode)
 	if err != nil {
 		return 0, err
@@ -77,17 +80,17 @@ func FindValue(hive *hivex.Hivex, parentNode int64, targetKey string) (int64, er
 		if err != nil {
 			return 0, err
 		}
-		if key == targetKey {
+		if strings.EqualFold(key, targetKey) {
 			return value, nil
 		}
 	}
 	return 0, nil
 }
 
-func UpsertNode(hive *hivex.Hivex, parent int64, targetName string) (int64, error) {
+func UpsertNode(hive hive.Hive, parent int64, targetName string) (int64, error) {
 	var targetNode int64
 	err := ReadNode(hive, parent, func(node int64, name string, err error) error {
-		if name == targetName {
+		if strings.EqualFold(name, targetName) {
 			targetNode = node
 			return SkipAll
 		}
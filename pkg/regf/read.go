@@ -0,0 +1,287 @@
+package regf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+const (
+	baseBlockSize = 0x1000
+	hbinAlignment = 0x1000
+
+	keyHiveEntry  = 0x0004
+	keyNoDelete   = 0x0008
+	keyCompName   = 0x0020
+	valueCompName = 0x0001
+
+	dataInline    = 0x80000000
+	bigDataLength = 16344
+)
+
+type reader struct {
+	hive  *Hive
+	data  []byte
+	depth int
+}
+
+func (h *Hive) load(data []byte) error {
+	if len(data) < baseBlockSize+0x20 || string(data[0:4]) != "regf" {
+		return fmt.Errorf("%w: missing regf signature", ErrCorrupt)
+	}
+	if binary.LittleEndian.Uint32(data[0x14:]) != 1 {
+		return fmt.Errorf("%w: unsupported major version %d", ErrCorrupt, binary.LittleEndian.Uint32(data[0x14:]))
+	}
+	if binary.LittleEndian.Uint32(data[0x04:]) != binary.LittleEndian.Uint32(data[0x08:]) {
+		return fmt.Errorf("%w: sequence numbers differ, let Windows replay the .LOG files first", ErrDirty)
+	}
+	h.header = append([]byte(nil), data[:baseBlockSize]...)
+
+	binsSize := int(binary.LittleEndian.Uint32(data[0x28:]))
+	if binsSize <= 0 || baseBlockSize+binsSize > len(data) {
+		binsSize = len(data) - baseBlockSize
+	}
+	r := &reader{
+		hive: h,
+		data: data[baseBlockSize : baseBlockSize+binsSize],
+	}
+	root, err := r.readKey(binary.LittleEndian.Uint32(data[0x24:]), nil)
+	if err != nil {
+		return err
+	}
+	h.root = root
+	return nil
+}
+
+// cell returns the payload of the cell at a bins-relative offset.
+func (r *reader) cell(offset uint32) ([]byte, error) {
+	if int64(offset)+4 > int64(len(r.data)) {
+		return nil, fmt.Errorf("%w: cell offset 0x%x out of range", ErrCorrupt, offset)
+	}
+	size := int32(binary.LittleEndian.Uint32(r.data[offset:]))
+	if size < 0 {
+		size = -size
+	}
+	if size < 4 || int64(offset)+int64(size) > int64(len(r.data)) {
+		return nil, fmt.Errorf("%w: bad cell size at 0x%x", ErrCorrupt, offset)
+	}
+	return r.data[offset+4 : offset+uint32(size)], nil
+}
+
+func (r *reader) readKey(offset uint32, parent *key) (*key, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > 512 {
+		return nil, fmt.Errorf("%w: key nesting too deep", ErrCorrupt)
+	}
+
+	c, err := r.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) < 0x4C || string(c[0:2]) != "nk" {
+		return nil, fmt.Errorf("%w: expected nk cell at 0x%x", ErrCorrupt, offset)
+	}
+	nameLen := int(binary.LittleEndian.Uint16(c[0x48:]))
+	classLen := int(binary.LittleEndian.Uint16(c[0x4A:]))
+	if 0x4C+nameLen > len(c) {
+		return nil, fmt.Errorf("%w: nk name out of range at 0x%x", ErrCorrupt, offset)
+	}
+	k := &key{
+		parent:    parent,
+		flags:     binary.LittleEndian.Uint16(c[0x02:]),
+		timestamp: binary.LittleEndian.Uint64(c[0x04:]),
+	}
+	k.name = decodeName(c[0x4C:0x4C+nameLen], k.flags&keyCompName != 0)
+	r.hive.register(k)
+
+	if classOffset := binary.LittleEndian.Uint32(c[0x30:]); classOffset != 0xFFFFFFFF && classLen > 0 {
+		class, err := r.cell(classOffset)
+		if err != nil {
+			return nil, err
+		}
+		if classLen <= len(class) {
+			k.class = append([]byte(nil), class[:classLen]...)
+		}
+	}
+
+	if securityOffset := binary.LittleEndian.Uint32(c[0x2C:]); securityOffset != 0xFFFFFFFF {
+		sk, err := r.cell(securityOffset)
+		if err != nil {
+			return nil, err
+		}
+		if len(sk) < 0x14 || string(sk[0:2]) != "sk" {
+			return nil, fmt.Errorf("%w: expected sk cell at 0x%x", ErrCorrupt, securityOffset)
+		}
+		descLen := int(binary.LittleEndian.Uint32(sk[0x10:]))
+		if 0x14+descLen > len(sk) {
+			return nil, fmt.Errorf("%w: sk descriptor out of range at 0x%x", ErrCorrupt, securityOffset)
+		}
+		k.security = append([]byte(nil), sk[0x14:0x14+descLen]...)
+	}
+
+	numValues := binary.LittleEndian.Uint32(c[0x24:])
+	if numValues > 0 {
+		list, err := r.cell(binary.LittleEndian.Uint32(c[0x28:]))
+		if err != nil {
+			return nil, err
+		}
+		if int(numValues)*4 > len(list) {
+			return nil, fmt.Errorf("%w: value list too short at 0x%x", ErrCorrupt, offset)
+		}
+		for i := 0; i < int(numValues); i++ {
+			v, err := r.readValue(binary.LittleEndian.Uint32(list[i*4:]), k)
+			if err != nil {
+				return nil, err
+			}
+			k.values = append(k.values, v)
+		}
+	}
+
+	if binary.LittleEndian.Uint32(c[0x14:]) > 0 {
+		var subkeys []uint32
+		if err = r.readSubkeyList(binary.LittleEndian.Uint32(c[0x1C:]), &subkeys, 0); err != nil {
+			return nil, err
+		}
+		for _, subkeyOffset := range subkeys {
+			child, err := r.readKey(subkeyOffset, k)
+			if err != nil {
+				return nil, err
+			}
+			k.children = append(k.children, child)
+		}
+	}
+	return k, nil
+}
+
+func (r *reader) readSubkeyList(offset uint32, out *[]uint32, depth int) error {
+	if depth > 2 {
+		return fmt.Errorf("%w: subkey index nesting too deep", ErrCorrupt)
+	}
+	c, err := r.cell(offset)
+	if err != nil {
+		return err
+	}
+	if len(c) < 4 {
+		return fmt.Errorf("%w: subkey list too short at 0x%x", ErrCorrupt, offset)
+	}
+	count := int(binary.LittleEndian.Uint16(c[2:]))
+	switch string(c[0:2]) {
+	case "lf", "lh":
+		if 4+count*8 > len(c) {
+			return fmt.Errorf("%w: subkey list too short at 0x%x", ErrCorrupt, offset)
+		}
+		for i := 0; i < count; i++ {
+			*out = append(*out, binary.LittleEndian.Uint32(c[4+i*8:]))
+		}
+	case "li":
+		if 4+count*4 > len(c) {
+			return fmt.Errorf("%w: subkey list too short at 0x%x", ErrCorrupt, offset)
+		}
+		for i := 0; i < count; i++ {
+			*out = append(*out, binary.LittleEndian.Uint32(c[4+i*4:]))
+		}
+	case "ri":
+		if 4+count*4 > len(c) {
+			return fmt.Errorf("%w: index root too short at 0x%x", ErrCorrupt, offset)
+		}
+		for i := 0; i < count; i++ {
+			if err = r.readSubkeyList(binary.LittleEndian.Uint32(c[4+i*4:]), out, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown subkey list %q at 0x%x", ErrCorrupt, c[0:2], offset)
+	}
+	return nil
+}
+
+func (r *reader) readValue(offset uint32, owner *key) (*value, error) {
+	c, err := r.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) < 0x14 || string(c[0:2]) != "vk" {
+		return nil, fmt.Errorf("%w: expected vk cell at 0x%x", ErrCorrupt, offset)
+	}
+	nameLen := int(binary.LittleEndian.Uint16(c[0x02:]))
+	dataSize := binary.LittleEndian.Uint32(c[0x04:])
+	dataOffset := binary.LittleEndian.Uint32(c[0x08:])
+	flags := binary.LittleEndian.Uint16(c[0x10:])
+	if 0x14+nameLen > len(c) {
+		return nil, fmt.Errorf("%w: vk name out of range at 0x%x", ErrCorrupt, offset)
+	}
+	v := &value{
+		owner: owner,
+		name:  decodeName(c[0x14:0x14+nameLen], flags&valueCompName != 0),
+		typ:   binary.LittleEndian.Uint32(c[0x0C:]),
+	}
+
+	if dataSize&dataInline != 0 {
+		size := dataSize &^ dataInline
+		if size > 4 {
+			size = 4
+		}
+		v.data = append([]byte(nil), c[0x08:0x08+size]...)
+	} else if dataSize > 0 {
+		v.data, err = r.readData(dataOffset, int(dataSize))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		v.data = []byte{}
+	}
+
+	r.hive.nextHandle++
+	v.handle = r.hive.nextHandle
+	r.hive.values[v.handle] = v
+	return v, nil
+}
+
+func (r *reader) readData(offset uint32, size int) ([]byte, error) {
+	c, err := r.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) >= 8 && string(c[0:2]) == "db" && size > bigDataLength {
+		count := int(binary.LittleEndian.Uint16(c[2:]))
+		list, err := r.cell(binary.LittleEndian.Uint32(c[4:]))
+		if err != nil {
+			return nil, err
+		}
+		if count*4 > len(list) {
+			return nil, fmt.Errorf("%w: big data list too short at 0x%x", ErrCorrupt, offset)
+		}
+		data := make([]byte, 0, size)
+		for i := 0; i < count && len(data) < size; i++ {
+			segment, err := r.cell(binary.LittleEndian.Uint32(list[i*4:]))
+			if err != nil {
+				return nil, err
+			}
+			n := min(size-len(data), bigDataLength, len(segment))
+			data = append(data, segment[:n]...)
+		}
+		if len(data) != size {
+			return nil, fmt.Errorf("%w: big data truncated at 0x%x", ErrCorrupt, offset)
+		}
+		return data, nil
+	}
+	if size > len(c) {
+		return nil, fmt.Errorf("%w: value data out of range at 0x%x", ErrCorrupt, offset)
+	}
+	return append([]byte(nil), c[:size]...), nil
+}
+
+func decodeName(raw []byte, compressed bool) string {
+	if compressed {
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	u16 := make([]uint16, len(raw)/2)
+	_ = binary.Read(bytes.NewReader(raw[:len(u16)*2]), binary.LittleEndian, &u16)
+	return string(utf16.Decode(u16))
+}
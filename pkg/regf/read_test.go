@@ -0,0 +1,48 @@
+package regf
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/jc-lab/go-bcdedit/internal/bcdtemplate"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseDirtyHive(t *testing.T) {
+	data := slices.Clone(bcdtemplate.EMPTY)
+	binary.LittleEndian.PutUint32(data[0x04:], binary.LittleEndian.Uint32(data[0x08:])+1)
+	for _, flags := range []int{READ, WRITE} {
+		if _, err := Parse(data, flags); !errors.Is(err, ErrDirty) {
+			t.Errorf("flags %d: got %v, want ErrDirty", flags, err)
+		}
+	}
+}
+
+func TestCommitRemovesLogs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "BCD")
+	if err := os.WriteFile(file, bcdtemplate.EMPTY, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, suffix := range logSuffixes {
+		if err := os.WriteFile(file+suffix, []byte("stale"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := Open(file, WRITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	for _, suffix := range logSuffixes {
+		if _, err := os.Stat(file + suffix); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s still exists: %v", suffix, err)
+		}
+	}
+	if _, err = Open(file, READ); err != nil {
+		t.Fatal(err)
+	}
+}
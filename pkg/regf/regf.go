@@ -0,0 +1,497 @@
+// Package regf is a pure Go reader/writer for Windows registry hive files.
+//
+// The whole hive is loaded into memory when opened. Nodes and values are
+// addressed by opaque non-zero handles, mirroring the hivex API, and Commit
+// serializes the tree back into a freshly laid out hive file.
+//
+// Transaction logs (BCD.LOG1, BCD.LOG2) are not replayed: a dirty hive,
+// whose last write Windows has not finished, is rejected with ErrDirty.
+package regf
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	READ  = 0
+	WRITE = 1
+)
+
+var (
+	ErrReadOnly      = errors.New("hive is not writable")
+	ErrInvalidHandle = errors.New("invalid node or value handle")
+	ErrCorrupt       = errors.New("corrupt registry hive")
+	ErrDirty         = errors.New("hive has pending changes in its transaction logs")
+)
+
+type HiveValue = hive.Value
+
+type key struct {
+	handle    int64
+	parent    *key
+	name      string
+	flags     uint16
+	timestamp uint64
+	class     []byte
+	security  []byte
+	children  []*key
+	values    []*value
+}
+
+type value struct {
+	handle int64
+	owner  *key
+	name   string
+	typ    uint32
+	data   []byte
+}
+
+var _ hive.Hive = (*Hive)(nil)
+
+type Hive struct {
+	file     string
+	writable bool
+
+	header []byte
+	root   *key
+
+	nextHandle int64
+	nodes      map[int64]*key
+	values     map[int64]*value
+}
+
+func Open(file string, flags int) (*Hive, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	h, err := Parse(data, flags)
+	if err != nil {
+		return nil, err
+	}
+	h.file = file
+	return h, nil
+}
+
+func Parse(data []byte, flags int) (*Hive, error) {
+	h := &Hive{
+		writable: flags&WRITE != 0,
+		nodes:    make(map[int64]*key),
+		values:   make(map[int64]*value),
+	}
+	if err := h.load(data); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Hive) Close() error {
+	h.root = nil
+	h.nodes = nil
+	h.values = nil
+	return nil
+}
+
+func (h *Hive) Root() (int64, error) {
+	if h.root == nil {
+		return 0, ErrInvalidHandle
+	}
+	return h.root.handle, nil
+}
+
+func (h *Hive) NodeName(node int64) (string, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return "", err
+	}
+	return k.name, nil
+}
+
+func (h *Hive) NodeTimestamp(node int64) (int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return 0, err
+	}
+	return int64(k.timestamp), nil
+}
+
+func (h *Hive) NodeChildren(node int64) ([]int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]int64, 0, len(k.children))
+	for _, child := range k.children {
+		children = append(children, child.handle)
+	}
+	return children, nil
+}
+
+func (h *Hive) NodeGetChild(node int64, name string) (int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return 0, err
+	}
+	for _, child := range k.children {
+		if strings.EqualFold(child.name, name) {
+			return child.handle, nil
+		}
+	}
+	return 0, nil
+}
+
+func (h *Hive) NodeParent(node int64) (int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return 0, err
+	}
+	if k.parent == nil {
+		return 0, nil
+	}
+	return k.parent.handle, nil
+}
+
+func (h *Hive) NodeValues(node int64) ([]int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int64, 0, len(k.values))
+	for _, v := range k.values {
+		values = append(values, v.handle)
+	}
+	return values, nil
+}
+
+func (h *Hive) NodeGetValue(node int64, name string) (int64, error) {
+	k, err := h.node(node)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range k.values {
+		if strings.EqualFold(v.name, name) {
+			return v.handle, nil
+		}
+	}
+	return 0, nil
+}
+
+func (h *Hive) NodeValueKey(val int64) (string, error) {
+	v, err := h.value(val)
+	if err != nil {
+		return "", err
+	}
+	return v.name, nil
+}
+
+func (h *Hive) NodeValueType(val int64) (valueType, length int64, err error) {
+	v, err := h.value(val)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(v.typ), int64(len(v.data)), nil
+}
+
+func (h *Hive) ValueValue(val int64) (valType int64, valueBytes []byte, err error) {
+	v, err := h.value(val)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int64(v.typ), append([]byte(nil), v.data...), nil
+}
+
+func (h *Hive) NodeAddChild(parent int64, name string) (int64, error) {
+	if !h.writable {
+		return 0, ErrReadOnly
+	}
+	p, err := h.node(parent)
+	if err != nil {
+		return 0, err
+	}
+	if name == "" || strings.Contains(name, "\\") || len(name) > 255 {
+		return 0, fmt.Errorf("invalid key name: %q", name)
+	}
+	for _, child := range p.children {
+		if strings.EqualFold(child.name, name) {
+			return 0, fmt.Errorf("key already exists: %s", name)
+		}
+	}
+	k := &key{
+		parent:    p,
+		name:      name,
+		timestamp: filetimeNow(),
+		security:  p.security,
+	}
+	h.register(k)
+	p.children = append(p.children, k)
+	p.timestamp = k.timestamp
+	return k.handle, nil
+}
+
+func (h *Hive) NodeDeleteChild(node int64) (int, error) {
+	if !h.writable {
+		return -1, ErrReadOnly
+	}
+	k, err := h.node(node)
+	if err != nil {
+		return -1, err
+	}
+	if k.parent == nil {
+		return -1, errors.New("cannot delete the root key")
+	}
+	p := k.parent
+	for i, child := range p.children {
+		if child == k {
+			p.children = append(p.children[:i], p.children[i+1:]...)
+			break
+		}
+	}
+	p.timestamp = filetimeNow()
+	h.unregister(k)
+	return 0, nil
+}
+
+func (h *Hive) NodeSetValue(node int64, val HiveValue) (int, error) {
+	if !h.writable {
+		return -1, ErrReadOnly
+	}
+	k, err := h.node(node)
+	if err != nil {
+		return -1, err
+	}
+	data := append([]byte(nil), val.Value...)
+	k.timestamp = filetimeNow()
+	for _, v := range k.values {
+		if strings.EqualFold(v.name, val.Key) {
+			v.typ = uint32(val.Type)
+			v.data = data
+			return 0, nil
+		}
+	}
+	v := &value{
+		owner: k,
+		name:  val.Key,
+		typ:   uint32(val.Type),
+		data:  data,
+	}
+	h.nextHandle++
+	v.handle = h.nextHandle
+	h.values[v.handle] = v
+	k.values = append(k.values, v)
+	return 0, nil
+}
+
+func (h *Hive) NodeDeleteValue(node int64, name string) (int, error) {
+	if !h.writable {
+		return -1, ErrReadOnly
+	}
+	k, err := h.node(node)
+	if err != nil {
+		return -1, err
+	}
+	for i, v := range k.values {
+		if strings.EqualFold(v.name, name) {
+			k.values = append(k.values[:i], k.values[i+1:]...)
+			delete(h.values, v.handle)
+			k.timestamp = filetimeNow()
+			return 0, nil
+		}
+	}
+	return -1, fmt.Errorf("value not found: %s", name)
+}
+
+// Bytes serializes the current tree into a hive image.
+func (h *Hive) Bytes() ([]byte, error) {
+	if h.root == nil {
+		return nil, ErrInvalidHandle
+	}
+	return h.serialize()
+}
+
+// Commit atomically replaces the hive file with the current tree and removes
+// the transaction logs next to it.
+func (h *Hive) Commit() (int, error) {
+	if !h.writable {
+		return -1, ErrReadOnly
+	}
+	if h.file == "" {
+		return -1, errors.New("hive has no backing file")
+	}
+	data, err := h.serialize()
+	if err != nil {
+		return -1, err
+	}
+	if err = WriteFileAtomic(h.file, data); err != nil {
+		return -1, err
+	}
+	if err = removeLogs(h.file); err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// logSuffixes are the transaction logs Windows keeps next to a hive.
+var logSuffixes = []string{".LOG", ".LOG1", ".LOG2"}
+
+// removeLogs deletes the transaction logs of a hive just committed. The
+// hive was clean when loaded, so they hold nothing it lacks, and Windows
+// must not replay them over the new layout.
+func removeLogs(file string) error {
+	for _, suffix := range logSuffixes {
+		if err := os.Remove(file + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to file, flushes it to
+// disk and renames it over file, so a crash leaves either the old or the new
+// contents but never a torn hive.
+func WriteFileAtomic(file string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	dir, base := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, file); err != nil {
+		return err
+	}
+
+	// persist the rename itself; not every filesystem supports syncing a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+var _ hive.Snapshotter = (*Hive)(nil)
+
+type snapshot struct {
+	header []byte
+	root   *key
+}
+
+func (h *Hive) Snapshot() (hive.Snapshot, error) {
+	if h.root == nil {
+		return nil, ErrInvalidHandle
+	}
+	return &snapshot{
+		header: append([]byte(nil), h.header...),
+		root:   cloneKey(h.root, nil, nil, nil),
+	}, nil
+}
+
+func (h *Hive) Restore(s hive.Snapshot) error {
+	saved, ok := s.(*snapshot)
+	if !ok {
+		return fmt.Errorf("not a regf snapshot: %T", s)
+	}
+	h.header = append([]byte(nil), saved.header...)
+	h.nodes = make(map[int64]*key)
+	h.values = make(map[int64]*value)
+	h.root = cloneKey(saved.root, nil, h.nodes, h.values)
+	return nil
+}
+
+// cloneKey deep copies a key tree keeping its handles, registering the copies
+// in nodes and values when they are non-nil.
+func cloneKey(k *key, parent *key, nodes map[int64]*key, values map[int64]*value) *key {
+	c := &key{
+		handle:    k.handle,
+		parent:    parent,
+		name:      k.name,
+		flags:     k.flags,
+		timestamp: k.timestamp,
+		class:     k.class,
+		security:  k.security,
+	}
+	if nodes != nil {
+		nodes[c.handle] = c
+	}
+	for _, v := range k.values {
+		copied := &value{
+			handle: v.handle,
+			owner:  c,
+			name:   v.name,
+			typ:    v.typ,
+			data:   append([]byte(nil), v.data...),
+		}
+		c.values = append(c.values, copied)
+		if values != nil {
+			values[copied.handle] = copied
+		}
+	}
+	for _, child := range k.children {
+		c.children = append(c.children, cloneKey(child, c, nodes, values))
+	}
+	return c
+}
+
+func (h *Hive) node(handle int64) (*key, error) {
+	k, ok := h.nodes[handle]
+	if !ok {
+		return nil, ErrInvalidHandle
+	}
+	return k, nil
+}
+
+func (h *Hive) value(handle int64) (*value, error) {
+	v, ok := h.values[handle]
+	if !ok {
+		return nil, ErrInvalidHandle
+	}
+	return v, nil
+}
+
+func (h *Hive) register(k *key) {
+	h.nextHandle++
+	k.handle = h.nextHandle
+	h.nodes[k.handle] = k
+}
+
+func (h *Hive) unregister(k *key) {
+	for _, child := range k.children {
+		h.unregister(child)
+	}
+	for _, v := range k.values {
+		delete(h.values, v.handle)
+	}
+	delete(h.nodes, k.handle)
+}
+
+// filetimeNow returns the current time as a Windows FILETIME.
+func filetimeNow() uint64 {
+	return uint64(time.Now().UnixNano()/100) + 116444736000000000
+}
@@ -0,0 +1,374 @@
+package regf
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxListEntries is the number of entries written into one lf/lh list before
+// an ri index root is used.
+const maxListEntries = 500
+
+// defaultSecurity is a self-relative security descriptor granting full
+// access to SYSTEM and Administrators, used when a hive has none.
+var defaultSecurity = []byte{
+	0x01, 0x00, 0x04, 0x8c, 0x14, 0x00, 0x00, 0x00, 0x24, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x05, 0x20, 0x00, 0x00, 0x00, 0x20, 0x02, 0x00, 0x00,
+	0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x12, 0x00, 0x00, 0x00,
+	0x02, 0x00, 0x34, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x02, 0x14, 0x00,
+	0x3f, 0x00, 0x0f, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+	0x12, 0x00, 0x00, 0x00, 0x00, 0x02, 0x18, 0x00, 0x3f, 0x00, 0x0f, 0x00,
+	0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x20, 0x00, 0x00, 0x00,
+	0x20, 0x02, 0x00, 0x00,
+}
+
+type writer struct {
+	minor     uint32
+	timestamp uint64
+
+	buf      []byte
+	binStart int
+	binEnd   int
+	pos      int
+
+	security map[string]uint32
+}
+
+func (h *Hive) serialize() ([]byte, error) {
+	header := append([]byte(nil), h.header...)
+	w := &writer{
+		minor:     binary.LittleEndian.Uint32(header[0x18:]),
+		timestamp: filetimeNow(),
+		security:  make(map[string]uint32),
+	}
+
+	w.writeSecurity(h.root)
+	rootOffset := w.writeKey(h.root, 0xFFFFFFFF)
+	w.closeBin()
+
+	sequence := binary.LittleEndian.Uint32(header[0x04:]) + 1
+	binary.LittleEndian.PutUint32(header[0x04:], sequence)
+	binary.LittleEndian.PutUint32(header[0x08:], sequence)
+	binary.LittleEndian.PutUint64(header[0x0C:], w.timestamp)
+	binary.LittleEndian.PutUint32(header[0x1C:], 0)
+	binary.LittleEndian.PutUint32(header[0x20:], 1)
+	binary.LittleEndian.PutUint32(header[0x24:], rootOffset)
+	binary.LittleEndian.PutUint32(header[0x28:], uint32(len(w.buf)))
+	binary.LittleEndian.PutUint32(header[0x2C:], 1)
+	binary.LittleEndian.PutUint32(header[0x1FC:], headerChecksum(header))
+
+	return append(header, w.buf...), nil
+}
+
+func headerChecksum(header []byte) uint32 {
+	var sum uint32
+	for i := 0; i < 0x1FC; i += 4 {
+		sum ^= binary.LittleEndian.Uint32(header[i:])
+	}
+	switch sum {
+	case 0:
+		return 1
+	case 0xFFFFFFFF:
+		return 0xFFFFFFFE
+	}
+	return sum
+}
+
+func (w *writer) newBin(minSize int) {
+	size := hbinAlignment
+	for size < minSize+0x20 {
+		size += hbinAlignment
+	}
+	w.binStart = len(w.buf)
+	w.binEnd = w.binStart + size
+	w.buf = append(w.buf, make([]byte, size)...)
+	bin := w.buf[w.binStart:]
+	copy(bin[0:4], "hbin")
+	binary.LittleEndian.PutUint32(bin[0x04:], uint32(w.binStart))
+	binary.LittleEndian.PutUint32(bin[0x08:], uint32(size))
+	binary.LittleEndian.PutUint64(bin[0x14:], w.timestamp)
+	w.pos = w.binStart + 0x20
+}
+
+// closeBin marks the unused tail of the current bin as a free cell.
+func (w *writer) closeBin() {
+	if w.binEnd > w.pos {
+		binary.LittleEndian.PutUint32(w.buf[w.pos:], uint32(w.binEnd-w.pos))
+		w.pos = w.binEnd
+	}
+}
+
+// alloc reserves an allocated cell for payload bytes and returns its offset.
+func (w *writer) alloc(payload int) uint32 {
+	size := (payload + 4 + 7) &^ 7
+	if w.buf == nil || w.binEnd-w.pos < size {
+		w.closeBin()
+		w.newBin(size)
+	}
+	offset := w.pos
+	binary.LittleEndian.PutUint32(w.buf[offset:], uint32(-int32(size)))
+	w.pos += size
+	return uint32(offset)
+}
+
+func (w *writer) cell(offset uint32) []byte {
+	return w.buf[offset+4:]
+}
+
+func (w *writer) writeSecurity(root *key) {
+	refs := make(map[string]uint32)
+	var order []string
+	var walk func(k *key, inherited []byte)
+	walk = func(k *key, inherited []byte) {
+		if k.security == nil {
+			k.security = inherited
+		}
+		descriptor := string(k.security)
+		if _, ok := refs[descriptor]; !ok {
+			order = append(order, descriptor)
+		}
+		refs[descriptor]++
+		for _, child := range k.children {
+			walk(child, k.security)
+		}
+	}
+	walk(root, defaultSecurity)
+
+	offsets := make([]uint32, len(order))
+	for i, descriptor := range order {
+		offsets[i] = w.alloc(0x14 + len(descriptor))
+		w.security[descriptor] = offsets[i]
+	}
+	for i, descriptor := range order {
+		c := w.cell(offsets[i])
+		copy(c[0:2], "sk")
+		binary.LittleEndian.PutUint32(c[0x04:], offsets[(i+1)%len(order)])
+		binary.LittleEndian.PutUint32(c[0x08:], offsets[(i+len(order)-1)%len(order)])
+		binary.LittleEndian.PutUint32(c[0x0C:], refs[descriptor])
+		binary.LittleEndian.PutUint32(c[0x10:], uint32(len(descriptor)))
+		copy(c[0x14:], descriptor)
+	}
+}
+
+func (w *writer) writeKey(k *key, parentOffset uint32) uint32 {
+	name, compressed := encodeName(k.name)
+	offset := w.alloc(0x4C + len(name))
+
+	children := slices.Clone(k.children)
+	slices.SortFunc(children, func(a, b *key) int {
+		return compareNames(a.name, b.name)
+	})
+	childOffsets := make([]uint32, len(children))
+	var maxSubkeyName, maxSubkeyClass uint32
+	for i, child := range children {
+		childOffsets[i] = w.writeKey(child, offset)
+		maxSubkeyName = max(maxSubkeyName, uint32(len(utf16.Encode([]rune(child.name)))*2))
+		maxSubkeyClass = max(maxSubkeyClass, uint32(len(child.class)))
+	}
+	subkeyList := uint32(0xFFFFFFFF)
+	if len(children) > 0 {
+		subkeyList = w.writeSubkeyList(children, childOffsets)
+	}
+
+	valueList := uint32(0xFFFFFFFF)
+	var maxValueName, maxValueData uint32
+	if len(k.values) > 0 {
+		valueOffsets := make([]uint32, len(k.values))
+		for i, v := range k.values {
+			valueOffsets[i] = w.writeValue(v)
+			maxValueName = max(maxValueName, uint32(len(utf16.Encode([]rune(v.name)))*2))
+			maxValueData = max(maxValueData, uint32(len(v.data)))
+		}
+		valueList = w.alloc(len(valueOffsets) * 4)
+		c := w.cell(valueList)
+		for i, valueOffset := range valueOffsets {
+			binary.LittleEndian.PutUint32(c[i*4:], valueOffset)
+		}
+	}
+
+	classOffset := uint32(0xFFFFFFFF)
+	if len(k.class) > 0 {
+		classOffset = w.alloc(len(k.class))
+		copy(w.cell(classOffset), k.class)
+	}
+
+	flags := k.flags &^ keyCompName
+	if compressed {
+		flags |= keyCompName
+	}
+	if parentOffset == 0xFFFFFFFF {
+		flags |= keyHiveEntry | keyNoDelete
+	}
+
+	c := w.cell(offset)
+	copy(c[0:2], "nk")
+	binary.LittleEndian.PutUint16(c[0x02:], flags)
+	binary.LittleEndian.PutUint64(c[0x04:], k.timestamp)
+	binary.LittleEndian.PutUint32(c[0x10:], parentOffset)
+	binary.LittleEndian.PutUint32(c[0x14:], uint32(len(children)))
+	binary.LittleEndian.PutUint32(c[0x1C:], subkeyList)
+	binary.LittleEndian.PutUint32(c[0x20:], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(c[0x24:], uint32(len(k.values)))
+	binary.LittleEndian.PutUint32(c[0x28:], valueList)
+	binary.LittleEndian.PutUint32(c[0x2C:], w.security[string(k.security)])
+	binary.LittleEndian.PutUint32(c[0x30:], classOffset)
+	binary.LittleEndian.PutUint32(c[0x34:], maxSubkeyName)
+	binary.LittleEndian.PutUint32(c[0x38:], maxSubkeyClass)
+	binary.LittleEndian.PutUint32(c[0x3C:], maxValueName)
+	binary.LittleEndian.PutUint32(c[0x40:], maxValueData)
+	binary.LittleEndian.PutUint16(c[0x48:], uint16(len(name)))
+	binary.LittleEndian.PutUint16(c[0x4A:], uint16(len(k.class)))
+	copy(c[0x4C:], name)
+	return offset
+}
+
+func (w *writer) writeSubkeyList(children []*key, offsets []uint32) uint32 {
+	if len(children) > maxListEntries {
+		var lists []uint32
+		for start := 0; start < len(children); start += maxListEntries {
+			end := min(start+maxListEntries, len(children))
+			lists = append(lists, w.writeLeaf(children[start:end], offsets[start:end]))
+		}
+		root := w.alloc(4 + len(lists)*4)
+		c := w.cell(root)
+		copy(c[0:2], "ri")
+		binary.LittleEndian.PutUint16(c[2:], uint16(len(lists)))
+		for i, list := range lists {
+			binary.LittleEndian.PutUint32(c[4+i*4:], list)
+		}
+		return root
+	}
+	return w.writeLeaf(children, offsets)
+}
+
+func (w *writer) writeLeaf(children []*key, offsets []uint32) uint32 {
+	list := w.alloc(4 + len(children)*8)
+	c := w.cell(list)
+	if w.minor >= 5 {
+		copy(c[0:2], "lh")
+	} else {
+		copy(c[0:2], "lf")
+	}
+	binary.LittleEndian.PutUint16(c[2:], uint16(len(children)))
+	for i, child := range children {
+		binary.LittleEndian.PutUint32(c[4+i*8:], offsets[i])
+		if w.minor >= 5 {
+			binary.LittleEndian.PutUint32(c[8+i*8:], nameHash(child.name))
+		} else {
+			copy(c[8+i*8:12+i*8], nameHint(child.name))
+		}
+	}
+	return list
+}
+
+func (w *writer) writeValue(v *value) uint32 {
+	name, compressed := encodeName(v.name)
+	offset := w.alloc(0x14 + len(name))
+
+	var dataSize, dataOffset uint32
+	switch {
+	case len(v.data) <= 4:
+		dataSize = uint32(len(v.data)) | dataInline
+	case len(v.data) > bigDataLength && w.minor >= 4:
+		dataSize = uint32(len(v.data))
+		dataOffset = w.writeBigData(v.data)
+	default:
+		dataSize = uint32(len(v.data))
+		dataOffset = w.alloc(len(v.data))
+		copy(w.cell(dataOffset), v.data)
+	}
+
+	var flags uint16
+	if compressed {
+		flags |= valueCompName
+	}
+	c := w.cell(offset)
+	copy(c[0:2], "vk")
+	binary.LittleEndian.PutUint16(c[0x02:], uint16(len(name)))
+	binary.LittleEndian.PutUint32(c[0x04:], dataSize)
+	if dataSize&dataInline != 0 {
+		copy(c[0x08:0x0C], v.data)
+	} else {
+		binary.LittleEndian.PutUint32(c[0x08:], dataOffset)
+	}
+	binary.LittleEndian.PutUint32(c[0x0C:], v.typ)
+	binary.LittleEndian.PutUint16(c[0x10:], flags)
+	copy(c[0x14:], name)
+	return offset
+}
+
+func (w *writer) writeBigData(data []byte) uint32 {
+	var segments []uint32
+	for start := 0; start < len(data); start += bigDataLength {
+		end := min(start+bigDataLength, len(data))
+		segment := w.alloc(end - start)
+		copy(w.cell(segment), data[start:end])
+		segments = append(segments, segment)
+	}
+	list := w.alloc(len(segments) * 4)
+	c := w.cell(list)
+	for i, segment := range segments {
+		binary.LittleEndian.PutUint32(c[i*4:], segment)
+	}
+	db := w.alloc(8)
+	c = w.cell(db)
+	copy(c[0:2], "db")
+	binary.LittleEndian.PutUint16(c[2:], uint16(len(segments)))
+	binary.LittleEndian.PutUint32(c[4:], list)
+	return db
+}
+
+// encodeName returns the on-disk name and whether it uses the compressed
+// (Latin-1) form.
+func encodeName(name string) ([]byte, bool) {
+	runes := []rune(name)
+	compressed := true
+	for _, r := range runes {
+		if r > 0xFF {
+			compressed = false
+			break
+		}
+	}
+	if compressed {
+		raw := make([]byte, len(runes))
+		for i, r := range runes {
+			raw[i] = byte(r)
+		}
+		return raw, true
+	}
+	u16 := utf16.Encode(runes)
+	raw := make([]byte, len(u16)*2)
+	for i, c := range u16 {
+		binary.LittleEndian.PutUint16(raw[i*2:], c)
+	}
+	return raw, false
+}
+
+func compareNames(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(strings.ToUpper(a))), utf16.Encode([]rune(strings.ToUpper(b))))
+}
+
+func nameHash(name string) uint32 {
+	var hash uint32
+	for _, c := range utf16.Encode([]rune(strings.ToUpper(name))) {
+		hash = hash*37 + uint32(c)
+	}
+	return hash
+}
+
+func nameHint(name string) []byte {
+	hint := make([]byte, 4)
+	for i, r := range []rune(name) {
+		if i >= 4 {
+			break
+		}
+		if r > 0xFF {
+			return make([]byte, 4)
+		}
+		hint[i] = byte(r)
+	}
+	return hint
+}
@@ -0,0 +1,338 @@
+package regf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/internal/bcdtemplate"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
+	"slices"
+	"testing"
+)
+
+func TestSerializeRoundTrip(t *testing.T) {
+	tests := []struct {
+		minor uint32
+		leaf  string
+		large string // signature of the cell holding a value above bigDataLength
+	}{
+		{3, "lf", ""},
+		{5, "lh", "db"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("1.%d", test.minor), func(t *testing.T) {
+			h, err := Parse(bcdtemplate.EMPTY, WRITE)
+			if err != nil {
+				t.Fatal(err)
+			}
+			binary.LittleEndian.PutUint32(h.header[0x18:], test.minor)
+			root, _ := h.Root()
+
+			// nested keys, one of them with a name that needs UTF-16
+			objects := mustAddChild(t, h, root, "Nested")
+			entry := mustAddChild(t, h, objects, "{9dea862c-5cdd-4e70-acc1-f32b344d4795}")
+			elements := mustAddChild(t, h, entry, "Elements")
+			element := mustAddChild(t, h, elements, "12000004")
+			mustAddChild(t, h, element, "Ünïcødé ключ")
+			mustSetValue(t, h, element, hive.Value{Type: hive.RegSz, Key: "Element", Value: []byte("W\x00i\x00n\x00\x00\x00")})
+			mustSetValue(t, h, element, hive.Value{Type: hive.RegDword, Key: "Small", Value: []byte{1, 0, 0, 0}})
+			mustSetValue(t, h, element, hive.Value{Type: hive.RegBinary, Key: "Empty", Value: []byte{}})
+
+			// more subkeys than fit into one lf/lh list, written through an ri index root
+			many := mustAddChild(t, h, root, "Many")
+			for i := 0; i < maxListEntries*2+17; i++ {
+				mustAddChild(t, h, many, fmt.Sprintf("Key%04d", i))
+			}
+
+			// values above bigDataLength go through db cells from version 1.4 on
+			large := make([]byte, bigDataLength*2+123)
+			for i := range large {
+				large[i] = byte(i * 7)
+			}
+			mustSetValue(t, h, element, hive.Value{Type: hive.RegBinary, Key: "Large", Value: large})
+
+			// a second descriptor, inherited by the subkeys of the key it is set on
+			secured, _ := h.node(entry)
+			secured.security = append(slices.Clone(defaultSecurity[:len(defaultSecurity)-4]), 0x21, 0x02, 0x00, 0x00)
+			secured.class = []byte("class")
+
+			data, err := h.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			reread, err := Parse(data, READ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareKeys(t, `\`, h.root, reread.root)
+
+			nestedKey, _ := reread.node(mustGetChild(t, reread, reread.root.handle, "Nested"))
+			if signature := cellSignature(t, data, nkField(t, data, nestedKey, 0x1C)); signature != test.leaf {
+				t.Errorf("Nested: subkey list is %q, want %s", signature, test.leaf)
+			}
+			manyKey, _ := reread.node(mustGetChild(t, reread, reread.root.handle, "Many"))
+			if signature := cellSignature(t, data, nkField(t, data, manyKey, 0x1C)); signature != "ri" {
+				t.Errorf("Many: subkey list is %q, want ri", signature)
+			}
+			if test.large != "" {
+				elementKey := nestedKey.children[0].children[0].children[0]
+				if signature := cellSignature(t, data, vkDataOffset(t, data, elementKey, "Large")); signature != test.large {
+					t.Errorf("Large: data cell is %q, want %s", signature, test.large)
+				}
+			}
+
+			// serializing the re-read hive keeps the same tree
+			again, err := reread.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			rereadAgain, err := Parse(again, READ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareKeys(t, `\`, reread.root, rereadAgain.root)
+		})
+	}
+}
+
+func compareKeys(t *testing.T, path string, want, got *key) {
+	t.Helper()
+	if want.name != got.name {
+		t.Errorf("%s: name %q, want %q", path, got.name, want.name)
+	}
+	if !bytes.Equal(want.class, got.class) {
+		t.Errorf("%s: class %q, want %q", path, got.class, want.class)
+	}
+	if !bytes.Equal(want.security, got.security) {
+		t.Errorf("%s: security descriptor differs", path)
+	}
+
+	if len(want.values) != len(got.values) {
+		t.Errorf("%s: %d values, want %d", path, len(got.values), len(want.values))
+	} else {
+		for i, v := range want.values {
+			if v.name != got.values[i].name || v.typ != got.values[i].typ || !bytes.Equal(v.data, got.values[i].data) {
+				t.Errorf("%s: value %q differs", path, v.name)
+			}
+		}
+	}
+
+	// subkeys are written sorted by name
+	children := slices.Clone(want.children)
+	slices.SortFunc(children, func(a, b *key) int {
+		return compareNames(a.name, b.name)
+	})
+	if len(children) != len(got.children) {
+		t.Errorf("%s: %d subkeys, want %d", path, len(got.children), len(children))
+		return
+	}
+	for i, child := range children {
+		compareKeys(t, path+child.name+`\`, child, got.children[i])
+	}
+}
+
+func mustAddChild(t *testing.T, h *Hive, parent int64, name string) int64 {
+	t.Helper()
+	node, err := h.NodeAddChild(parent, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func mustGetChild(t *testing.T, h *Hive, parent int64, name string) int64 {
+	t.Helper()
+	node, err := h.NodeGetChild(parent, name)
+	if err != nil || node == 0 {
+		t.Fatalf("subkey %s not found: %v", name, err)
+	}
+	return node
+}
+
+func mustSetValue(t *testing.T, h *Hive, node int64, val hive.Value) {
+	t.Helper()
+	if _, err := h.NodeSetValue(node, val); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nkField returns a 32-bit field of the nk cell of k, found by walking the
+// serialized tree from the root along the names of its ancestors.
+func nkField(t *testing.T, data []byte, k *key, field int) uint32 {
+	t.Helper()
+	var path []*key
+	for p := k; p.parent != nil; p = p.parent {
+		path = append([]*key{p}, path...)
+	}
+	r := &reader{data: data[baseBlockSize:]}
+	offset := binary.LittleEndian.Uint32(data[0x24:])
+	for _, step := range path {
+		c, err := r.cell(offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var subkeys []uint32
+		if err = r.readSubkeyList(binary.LittleEndian.Uint32(c[0x1C:]), &subkeys, 0); err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, subkey := range subkeys {
+			sc, err := r.cell(subkey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nameLen := int(binary.LittleEndian.Uint16(sc[0x48:]))
+			flags := binary.LittleEndian.Uint16(sc[0x02:])
+			if decodeName(sc[0x4C:0x4C+nameLen], flags&keyCompName != 0) == step.name {
+				offset, found = subkey, true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("subkey %s not found", step.name)
+		}
+	}
+	c, err := r.cell(offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return binary.LittleEndian.Uint32(c[field:])
+}
+
+// vkDataOffset returns the data offset of the named value of k.
+func vkDataOffset(t *testing.T, data []byte, k *key, name string) uint32 {
+	t.Helper()
+	r := &reader{data: data[baseBlockSize:]}
+	list, err := r.cell(nkField(t, data, k, 0x28))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range k.values {
+		c, err := r.cell(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nameLen := int(binary.LittleEndian.Uint16(c[0x02:]))
+		flags := binary.LittleEndian.Uint16(c[0x10:])
+		if decodeName(c[0x14:0x14+nameLen], flags&valueCompName != 0) == name {
+			return binary.LittleEndian.Uint32(c[0x08:])
+		}
+	}
+	t.Fatalf("value %s not found", name)
+	return 0
+}
+
+func cellSignature(t *testing.T, data []byte, offset uint32) string {
+	t.Helper()
+	c, err := (&reader{data: data[baseBlockSize:]}).cell(offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(c[0:2])
+}
+
+// TestSerializeWindowsHive changes the empty store written by Windows
+// bcdedit /createstore and checks that the rewritten file keeps what
+// Windows relies on.
+func TestSerializeWindowsHive(t *testing.T) {
+	original := bcdtemplate.EMPTY
+	h, err := Parse(original, WRITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := h.Root()
+	objects := mustGetChild(t, h, root, "Objects")
+	for i := 0; i < maxListEntries+3; i++ {
+		entry := mustAddChild(t, h, objects, fmt.Sprintf("{%08x-0000-4000-8000-000000000000}", i))
+		mustSetValue(t, h, entry, hive.Value{Type: hive.RegDword, Key: "Type", Value: []byte{2, 0, 0, 0x10}})
+	}
+	mustSetValue(t, h, objects, hive.Value{Type: hive.RegBinary, Key: "Large", Value: make([]byte, bigDataLength+1)})
+
+	data, err := h.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reread, err := Parse(data, READ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareKeys(t, `\`, h.root, reread.root)
+
+	if checksum := binary.LittleEndian.Uint32(original[0x1FC:]); checksum != headerChecksum(original) {
+		t.Errorf("Windows header checksum %08x, computed %08x", checksum, headerChecksum(original))
+	}
+	if checksum := binary.LittleEndian.Uint32(data[0x1FC:]); checksum != headerChecksum(data) {
+		t.Errorf("header checksum %08x, want %08x", checksum, headerChecksum(data))
+	}
+	sequence := binary.LittleEndian.Uint32(original[0x04:]) + 1
+	if seq1, seq2 := binary.LittleEndian.Uint32(data[0x04:]), binary.LittleEndian.Uint32(data[0x08:]); seq1 != sequence || seq2 != sequence {
+		t.Errorf("sequence numbers %d/%d, want %d/%d", seq1, seq2, sequence, sequence)
+	}
+	if !bytes.Equal(data[0x14:0x1C], original[0x14:0x1C]) || !bytes.Equal(data[0x30:0x70], original[0x30:0x70]) {
+		t.Errorf("version or file name changed")
+	}
+
+	// version 1.3 hives use lf lists and keep big values in a single cell
+	objectsKey, _ := reread.node(mustGetChild(t, reread, reread.root.handle, "Objects"))
+	if signature := cellSignature(t, data, nkField(t, data, objectsKey, 0x1C)); signature != "ri" {
+		t.Errorf("Objects: subkey list is %q, want ri", signature)
+	}
+	r := &reader{data: data[baseBlockSize:]}
+	ri, _ := r.cell(nkField(t, data, objectsKey, 0x1C))
+	if signature := cellSignature(t, data, binary.LittleEndian.Uint32(ri[4:])); signature != "lf" {
+		t.Errorf("Objects: leaf list is %q, want lf", signature)
+	}
+	if signature := cellSignature(t, data, vkDataOffset(t, data, objectsKey, "Large")); signature == "db" {
+		t.Errorf("Large: version 1.3 hive got a db cell")
+	}
+
+	// the descriptors are written unchanged, counting the keys that use them
+	want := securityCells(t, original)
+	got := securityCells(t, data)
+	if len(got) != len(want) {
+		t.Fatalf("%d security cells, want %d", len(got), len(want))
+	}
+	uses := make(map[string]uint32)
+	countSecurity(reread.root, uses)
+	for descriptor := range want {
+		if _, ok := got[descriptor]; !ok {
+			t.Errorf("security descriptor %x lost", descriptor)
+		} else if got[descriptor] != uses[descriptor] {
+			t.Errorf("security descriptor referenced %d times, counted %d", got[descriptor], uses[descriptor])
+		}
+	}
+}
+
+// securityCells returns the descriptors of all sk cells with their
+// reference counts.
+func securityCells(t *testing.T, data []byte) map[string]uint32 {
+	t.Helper()
+	cells := make(map[string]uint32)
+	bins := data[baseBlockSize : baseBlockSize+int(binary.LittleEndian.Uint32(data[0x28:]))]
+	for bin := 0; bin < len(bins); {
+		if string(bins[bin:bin+4]) != "hbin" {
+			t.Fatalf("no hbin at 0x%x", bin)
+		}
+		binSize := int(binary.LittleEndian.Uint32(bins[bin+0x08:]))
+		for offset := bin + 0x20; offset < bin+binSize; {
+			size := int32(binary.LittleEndian.Uint32(bins[offset:]))
+			if size < 0 {
+				size = -size
+				if c := bins[offset+4 : offset+int(size)]; string(c[0:2]) == "sk" {
+					length := binary.LittleEndian.Uint32(c[0x10:])
+					cells[string(c[0x14:0x14+length])] = binary.LittleEndian.Uint32(c[0x0C:])
+				}
+			}
+			offset += int(size)
+		}
+		bin += binSize
+	}
+	return cells
+}
+
+func countSecurity(k *key, uses map[string]uint32) {
+	uses[string(k.security)]++
+	for _, child := range k.children {
+		countSecurity(child, uses)
+	}
+}
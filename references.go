@@ -0,0 +1,81 @@
+package go_bcdedit
+
+import (
+	"cmp"
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+)
+
+// ReferenceIndex holds every reference between the objects of a store, so
+// that entries can be checked for users before they are deleted or moved.
+// It is a snapshot; rebuild it after changing the store.
+type ReferenceIndex struct {
+	inbound  map[string][]*model.Reference
+	outbound map[string][]*model.Reference
+}
+
+// NewReferenceIndex collects the object, object list (e.g. displayorder,
+// inherit) and device options references of all objects in bcd.
+func NewReferenceIndex(bcd Bcdedit) (*ReferenceIndex, error) {
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for id := range objects {
+		exists[strings.ToLower(id)] = true
+	}
+
+	index := &ReferenceIndex{
+		inbound:  make(map[string][]*model.Reference),
+		outbound: make(map[string][]*model.Reference),
+	}
+	for id, object := range objects {
+		application := object.GetDescription().ApplicationType()
+		for key, element := range object.GetElements() {
+			for _, ref := range elementReferences(key, element) {
+				reference := &model.Reference{
+					From:     id,
+					To:       ref,
+					Element:  key.String(),
+					Dangling: !exists[strings.ToLower(ref)] && !strings.EqualFold(ref, CurrentObjectId),
+				}
+				if option := model.FindOptionByType(key, application); option != nil {
+					reference.Name = option.Name
+				}
+				index.outbound[strings.ToLower(id)] = append(index.outbound[strings.ToLower(id)], reference)
+				index.inbound[strings.ToLower(ref)] = append(index.inbound[strings.ToLower(ref)], reference)
+			}
+		}
+	}
+	for _, references := range index.outbound {
+		sortReferences(references)
+	}
+	for _, references := range index.inbound {
+		sortReferences(references)
+	}
+	return index, nil
+}
+
+// Inbound returns the references pointing at the object.
+func (r *ReferenceIndex) Inbound(objectId string) []*model.Reference {
+	return r.inbound[strings.ToLower(objectId)]
+}
+
+// Outbound returns the references the object's elements make.
+func (r *ReferenceIndex) Outbound(objectId string) []*model.Reference {
+	return r.outbound[strings.ToLower(objectId)]
+}
+
+func sortReferences(references []*model.Reference) {
+	slices.SortFunc(references, func(a, b *model.Reference) int {
+		if c := cmp.Compare(strings.ToLower(a.From), strings.ToLower(b.From)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Element, b.Element); c != 0 {
+			return c
+		}
+		return cmp.Compare(strings.ToLower(a.To), strings.ToLower(b.To))
+	})
+}
@@ -0,0 +1,75 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"testing"
+)
+
+func referenceStrings(references []*model.Reference) []string {
+	var s []string
+	for _, reference := range references {
+		line := reference.From + " " + reference.Element + " " + reference.To
+		if reference.Dangling {
+			line += " dangling"
+		}
+		s = append(s, line)
+	}
+	return s
+}
+
+func TestReferenceIndex(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	loader := mustGetObject(t, bcd, testLoaderId)
+	ramdisk := model.NewRamdiskDevice(model.NewBootDevice().DeviceDescriptor, `\sources\boot.wim`, testSettingsId)
+	mustSet(t)(loader.SetDevice(model.ElementApplicationDevice, ramdisk))
+	// a device whose descriptor does not parse still names its options object
+	options := mustParseGuid(t, testOtherId)
+	mustSet(t)(loader.SetElement(model.ElementOsDevice, RegBinary, append(options[:], 0xff, 0xff)))
+	mustSet(t)(loader.SetObject(model.ElementResumeObject, CurrentObjectId))
+
+	index, err := NewReferenceIndex(bcd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbound := referenceStrings(index.Inbound(testLoaderId))
+	want := []string{
+		BootMgrObjectId + " 23000003 " + testLoaderId,
+		BootMgrObjectId + " 24000001 " + testLoaderId,
+	}
+	if !slices.Equal(inbound, want) {
+		t.Errorf("inbound: got %q, want %q", inbound, want)
+	}
+
+	outbound := referenceStrings(index.Outbound(testLoaderId))
+	want = []string{
+		testLoaderId + " 11000001 " + testSettingsId + " dangling",
+		testLoaderId + " 21000001 " + testOtherId + " dangling",
+		testLoaderId + " 23000006 " + CurrentObjectId,
+	}
+	if !slices.Equal(outbound, want) {
+		t.Errorf("outbound: got %q, want %q", outbound, want)
+	}
+	if got := index.Inbound(testOtherId); len(got) != 1 || got[0].Name != "osdevice" {
+		t.Errorf("inbound of the options object: %q", referenceStrings(got))
+	}
+	if got := index.Outbound(testOtherId); len(got) != 0 {
+		t.Errorf("outbound of a missing object: %q", referenceStrings(got))
+	}
+
+	issues, err := bcd.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dangling int
+	for _, issue := range issues {
+		if issue.Kind == model.IssueDanglingReference {
+			dangling++
+		}
+	}
+	if dangling != 2 {
+		t.Errorf("%d dangling references reported, want 2", dangling)
+	}
+}
@@ -0,0 +1,229 @@
+package go_bcdedit
+
+import (
+	"bytes"
+	"github.com/jc-lab/go-bcdedit/model"
+	"sync"
+)
+
+// SyncBcdedit makes a Bcdedit safe for concurrent use. Reads share a read
+// lock and return snapshots that never change afterwards; writes, including
+// the setters of returned objects, take the write lock and go to the live
+// store. Transactions are store-wide, not per goroutine.
+//
+// HiveBcdedit and the objects and elements it returns are not safe for
+// concurrent use; SyncBcdedit and everything it returns are.
+type SyncBcdedit struct {
+	mu  sync.RWMutex
+	bcd Bcdedit
+}
+
+var _ Bcdedit = (*SyncBcdedit)(nil)
+
+func NewSyncBcdedit(bcd Bcdedit) *SyncBcdedit {
+	return &SyncBcdedit{bcd: bcd}
+}
+
+func (s *SyncBcdedit) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bcd.Close()
+}
+
+func (s *SyncBcdedit) Enumerate(objectId string) (map[string]BcdObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	objectMap, err := s.bcd.Enumerate(objectId)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[string]BcdObject, len(objectMap))
+	for id, object := range objectMap {
+		snapshots[id] = s.snapshot(object)
+	}
+	return snapshots, nil
+}
+
+func (s *SyncBcdedit) GetObject(objectId string) (BcdObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, err := s.bcd.GetObject(objectId)
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshot(object), nil
+}
+
+func (s *SyncBcdedit) UpsertObject(objectId string, description model.BcdDescription) (BcdObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, err := s.bcd.UpsertObject(objectId, description)
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshot(object), nil
+}
+
+func (s *SyncBcdedit) DeleteObject(objectId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bcd.DeleteObject(objectId)
+}
+
+func (s *SyncBcdedit) CopyObject(srcId string, newId string, description string) (BcdObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, err := s.bcd.CopyObject(srcId, newId, description)
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshot(object), nil
+}
+
+func (s *SyncBcdedit) ResolveObjectId(objectId string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bcd.ResolveObjectId(objectId)
+}
+
+func (s *SyncBcdedit) Validate() ([]*model.ValidationIssue, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bcd.Validate()
+}
+
+func (s *SyncBcdedit) Begin() error {
+	return s.write(s.bcd.Begin)
+}
+
+func (s *SyncBcdedit) Commit() error {
+	return s.write(s.bcd.Commit)
+}
+
+func (s *SyncBcdedit) Rollback() error {
+	return s.write(s.bcd.Rollback)
+}
+
+func (s *SyncBcdedit) Savepoint(name string) error {
+	return s.write(func() error {
+		return s.bcd.Savepoint(name)
+	})
+}
+
+func (s *SyncBcdedit) RollbackTo(name string) error {
+	return s.write(func() error {
+		return s.bcd.RollbackTo(name)
+	})
+}
+
+func (s *SyncBcdedit) write(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn()
+}
+
+func (s *SyncBcdedit) snapshot(object BcdObject) *SyncBcdObject {
+	copied := &HiveBcdObject{
+		Id:          object.GetId(),
+		Description: object.GetDescription(),
+		Elements:    make(map[model.ElementType]*HiveBcdElement),
+	}
+	for key, element := range object.GetElements() {
+		copied.Elements[key] = snapshotElement(copied, key, element)
+	}
+	return &SyncBcdObject{
+		HiveBcdObject: copied,
+		sync:          s,
+	}
+}
+
+func snapshotElement(parent *HiveBcdObject, key model.ElementType, element BcdElement) *HiveBcdElement {
+	return &HiveBcdElement{
+		Parent: parent,
+		Key:    key,
+		Type:   element.GetType(),
+		Raw:    bytes.Clone(element.GetRaw()),
+	}
+}
+
+// SyncBcdObject is an immutable snapshot of an object returned by
+// SyncBcdedit. Its setters write to the live object in the store and return
+// a snapshot of the new element; the object itself keeps the values it was
+// taken with, so fetch it again to see the change.
+type SyncBcdObject struct {
+	*HiveBcdObject
+	sync *SyncBcdedit
+}
+
+func (o *SyncBcdObject) update(fn func(live BcdObject) (BcdElement, error)) (BcdElement, error) {
+	o.sync.mu.Lock()
+	defer o.sync.mu.Unlock()
+	live, err := o.sync.bcd.GetObject(o.Id)
+	if err != nil {
+		return nil, err
+	}
+	element, err := fn(live)
+	if err != nil {
+		return nil, err
+	}
+	hiveElement, ok := element.(*HiveBcdElement)
+	if !ok {
+		return element, nil
+	}
+	return snapshotElement(o.HiveBcdObject, hiveElement.Key, element), nil
+}
+
+func (o *SyncBcdObject) SetElement(key model.ElementType, typ ValueType, raw []byte) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetElement(key, typ, raw)
+	})
+}
+
+func (o *SyncBcdObject) SetString(key model.ElementType, value string) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetString(key, value)
+	})
+}
+
+func (o *SyncBcdObject) SetBoolean(key model.ElementType, value bool) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetBoolean(key, value)
+	})
+}
+
+func (o *SyncBcdObject) SetInteger(key model.ElementType, value uint64) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetInteger(key, value)
+	})
+}
+
+func (o *SyncBcdObject) SetIntegerList(key model.ElementType, values []uint64) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetIntegerList(key, values)
+	})
+}
+
+func (o *SyncBcdObject) SetObject(key model.ElementType, objectId string) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetObject(key, objectId)
+	})
+}
+
+func (o *SyncBcdObject) SetObjectList(key model.ElementType, objectIds []string) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetObjectList(key, objectIds)
+	})
+}
+
+func (o *SyncBcdObject) SetDevice(key model.ElementType, device *model.Device) (BcdElement, error) {
+	return o.update(func(live BcdObject) (BcdElement, error) {
+		return live.SetDevice(key, device)
+	})
+}
+
+func (o *SyncBcdObject) DeleteElement(key model.ElementType) error {
+	_, err := o.update(func(live BcdObject) (BcdElement, error) {
+		return nil, live.DeleteElement(key)
+	})
+	return err
+}
@@ -0,0 +1,98 @@
+package go_bcdedit
+
+import (
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"sync"
+	"testing"
+)
+
+func TestSyncBcdedit(t *testing.T) {
+	bcd := NewSyncBcdedit(newTestStore(t))
+	defer bcd.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("{%08x-0000-4000-8000-000000000000}", i)
+			for j := 0; j < 20; j++ {
+				object, err := bcd.UpsertObject(id, osloaderType)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err = object.SetInteger(model.ElementType(0x25000020), uint64(j)); err != nil {
+					errs <- err
+					return
+				}
+				if _, err = object.SetString(model.ElementDescription, fmt.Sprintf("entry %d", j)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				objects, err := bcd.Enumerate("all")
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, object := range objects {
+					for _, element := range object.GetElements() {
+						element.GetRaw()
+					}
+				}
+				if _, err = bcd.GetObject(testLoaderId); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	objects, err := bcd.Enumerate("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 10 {
+		t.Errorf("%d objects, want 10", len(objects))
+	}
+}
+
+func TestSyncBcdeditSnapshot(t *testing.T) {
+	bcd := NewSyncBcdedit(newTestStore(t))
+	defer bcd.Close()
+
+	object, err := bcd.GetObject(testLoaderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := string(object.GetElements()[model.ElementDescription].GetRaw())
+	element, err := object.SetString(model.ElementDescription, "Changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(object.GetElements()[model.ElementDescription].GetRaw()) != raw {
+		t.Error("the snapshot changed with the store")
+	}
+	if string(element.GetRaw()) == raw {
+		t.Error("the setter returned the old value")
+	}
+	fresh, err := bcd.GetObject(testLoaderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fresh.GetElements()[model.ElementDescription].GetRaw()) != string(element.GetRaw()) {
+		t.Error("the change is not in the store")
+	}
+}
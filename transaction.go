@@ -0,0 +1,95 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/pkg/hive"
+)
+
+var (
+	ErrNotWritable       = errors.New("store is not writable")
+	ErrNoTransaction     = errors.New("no transaction in progress")
+	ErrTransactionActive = errors.New("transaction already in progress")
+)
+
+// Begin starts a transaction. While it is open the store is only written
+// by Commit, and Close rolls back whatever was not committed. Once it has
+// ended, later changes are written by Close again.
+// Objects and elements read before a rollback must be fetched again.
+func (b *HiveBcdedit) Begin() error {
+	if !b.Writable {
+		return ErrNotWritable
+	}
+	if b.savepoints != nil {
+		return ErrTransactionActive
+	}
+	snapshot, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+	b.savepoints = []savepoint{{snapshot: snapshot}}
+	return nil
+}
+
+// Commit writes the store and ends the transaction. If writing fails the
+// transaction stays open and the store on disk is left untouched.
+func (b *HiveBcdedit) Commit() error {
+	if b.savepoints == nil {
+		return ErrNoTransaction
+	}
+	if _, err := b.Hive.Commit(); err != nil {
+		return err
+	}
+	b.savepoints = nil
+	return nil
+}
+
+// Rollback discards all changes made since Begin and ends the transaction.
+func (b *HiveBcdedit) Rollback() error {
+	if b.savepoints == nil {
+		return ErrNoTransaction
+	}
+	err := b.Hive.(hive.Snapshotter).Restore(b.savepoints[0].snapshot)
+	b.savepoints = nil
+	return err
+}
+
+// Savepoint marks the current state of the transaction under name.
+func (b *HiveBcdedit) Savepoint(name string) error {
+	if b.savepoints == nil {
+		return ErrNoTransaction
+	}
+	snapshot, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+	b.savepoints = append(b.savepoints, savepoint{name: name, snapshot: snapshot})
+	return nil
+}
+
+// RollbackTo discards the changes made since the latest savepoint called
+// name. The savepoint itself is kept, later ones are released.
+func (b *HiveBcdedit) RollbackTo(name string) error {
+	if b.savepoints == nil {
+		return ErrNoTransaction
+	}
+	for i := len(b.savepoints) - 1; i > 0; i-- {
+		if b.savepoints[i].name != name {
+			continue
+		}
+		if err := b.Hive.(hive.Snapshotter).Restore(b.savepoints[i].snapshot); err != nil {
+			return err
+		}
+		b.savepoints = b.savepoints[:i+1]
+		return nil
+	}
+	return fmt.Errorf("no savepoint %q", name)
+}
+
+func (b *HiveBcdedit) snapshot() (hive.Snapshot, error) {
+	snapshotter, ok := b.Hive.(hive.Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("hive %T does not support transactions", b.Hive)
+	}
+	return snapshotter.Snapshot()
+}
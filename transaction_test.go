@@ -0,0 +1,170 @@
+package go_bcdedit
+
+import (
+	"errors"
+	"github.com/jc-lab/go-bcdedit/model"
+	"path/filepath"
+	"testing"
+)
+
+func description(t *testing.T, bcd Bcdedit, id string) string {
+	t.Helper()
+	element, ok := mustGetObject(t, bcd, id).GetElements()[model.ElementDescription]
+	if !ok {
+		return ""
+	}
+	value, err := element.(*HiveBcdElement).GetString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func setDescription(t *testing.T, bcd Bcdedit, id string, value string) {
+	t.Helper()
+	mustSet(t)(mustGetObject(t, bcd, id).SetString(model.ElementDescription, value))
+}
+
+func TestRollback(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	if err := bcd.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	setDescription(t, bcd, testLoaderId, "Changed")
+	if _, err := bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err := bcd.DeleteObject("{bootmgr}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bcd.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := description(t, bcd, testLoaderId); got != "Windows" {
+		t.Errorf("description %q after rollback", got)
+	}
+	if _, err := bcd.GetObject(testOtherId); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("created object after rollback: %v", err)
+	}
+	mustGetObject(t, bcd, "{bootmgr}")
+	if err := bcd.Rollback(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("second rollback: got %v, want ErrNoTransaction", err)
+	}
+}
+
+func TestSavepoint(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	if err := bcd.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	setDescription(t, bcd, testLoaderId, "First")
+	if err := bcd.Savepoint("a"); err != nil {
+		t.Fatal(err)
+	}
+	setDescription(t, bcd, testLoaderId, "Second")
+	if err := bcd.Savepoint("b"); err != nil {
+		t.Fatal(err)
+	}
+	setDescription(t, bcd, testLoaderId, "Third")
+
+	if err := bcd.RollbackTo("a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := description(t, bcd, testLoaderId); got != "First" {
+		t.Errorf("description %q after RollbackTo(a)", got)
+	}
+	if err := bcd.RollbackTo("b"); err == nil {
+		t.Error("savepoint b was released by RollbackTo(a)")
+	}
+	setDescription(t, bcd, testLoaderId, "Fourth")
+	if err := bcd.RollbackTo("a"); err != nil {
+		t.Errorf("savepoint a is kept: %v", err)
+	}
+	if got := description(t, bcd, testLoaderId); got != "First" {
+		t.Errorf("description %q after the second RollbackTo(a)", got)
+	}
+	if err := bcd.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := description(t, bcd, testLoaderId); got != "Windows" {
+		t.Errorf("description %q after rollback", got)
+	}
+}
+
+func TestTransactionErrors(t *testing.T) {
+	bcd := newTestStore(t)
+	defer bcd.Close()
+
+	if err := bcd.Commit(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("commit: got %v, want ErrNoTransaction", err)
+	}
+	if err := bcd.Savepoint("a"); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("savepoint: got %v, want ErrNoTransaction", err)
+	}
+	if err := bcd.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bcd.Begin(); !errors.Is(err, ErrTransactionActive) {
+		t.Errorf("nested begin: got %v, want ErrTransactionActive", err)
+	}
+	bcd.(*HiveBcdedit).Writable = false
+	if err := bcd.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bcd.Begin(); !errors.Is(err, ErrNotWritable) {
+		t.Errorf("read-only begin: got %v, want ErrNotWritable", err)
+	}
+}
+
+func TestTransactionFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "BCD")
+	bcd, err := CreateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bcd.UpsertObject(testLoaderId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Changes after the transaction are written by Close.
+	if _, err = bcd.UpsertObject(testOtherId, osloaderType); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bcd, err = OpenStore(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustGetObject(t, bcd, testLoaderId)
+	mustGetObject(t, bcd, testOtherId)
+	// An open transaction is discarded by Close.
+	if err = bcd.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.DeleteObject(testOtherId); err != nil {
+		t.Fatal(err)
+	}
+	if err = bcd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bcd, err = OpenStore(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bcd.Close()
+	mustGetObject(t, bcd, testOtherId)
+}
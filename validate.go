@@ -0,0 +1,164 @@
+package go_bcdedit
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/jc-lab/go-bcdedit/model"
+	"slices"
+	"strings"
+)
+
+// CurrentObjectId is resolved by the boot manager at runtime and never
+// stored, so references to it are not dangling.
+const CurrentObjectId = "{fa926493-6f1c-4193-a414-58f0b2456d1e}"
+
+// MandatoryElements lists the elements an application cannot start without.
+var MandatoryElements = map[model.ApplicationType][]model.ElementType{
+	model.ApplicationOsloader: {
+		model.ElementApplicationDevice,
+		model.ElementApplicationPath,
+		model.ElementOsDevice,
+		model.ElementSystemRoot,
+	},
+	model.ApplicationResume: {
+		model.ElementApplicationDevice,
+		model.ElementApplicationPath,
+	},
+}
+
+// Validate checks the store for dangling references, inheritance cycles,
+// elements stored with the wrong registry type or in the wrong kind of
+// object, missing mandatory elements and unknown element types.
+func (b *HiveBcdedit) Validate() ([]*model.ValidationIssue, error) {
+	objectMap, err := b.Enumerate("all")
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for id := range objectMap {
+		exists[strings.ToLower(id)] = true
+	}
+
+	var issues []*model.ValidationIssue
+	report := func(kind model.ValidationIssueKind, objectId string, key model.ElementType, format string, args ...interface{}) {
+		issue := &model.ValidationIssue{
+			Kind:     kind,
+			ObjectId: objectId,
+			Message:  fmt.Sprintf(format, args...),
+		}
+		if key != 0 {
+			issue.Element = key.String()
+		}
+		issues = append(issues, issue)
+	}
+
+	for id, object := range objectMap {
+		hiveObject := object.(*HiveBcdObject)
+		description := object.GetDescription()
+
+		for key, element := range hiveObject.Elements {
+			expected := ValueTypeFromJson(key.Format().ValueType())
+			if key.Format().ValueType() != "" && element.Type != expected {
+				report(model.IssueTypeMismatch, id, key, "%s element stored as %s, expected %s", key.Format(), element.Type.ToJson(), expected.ToJson())
+				continue
+			}
+			if !classMatches(description, key.Class()) {
+				report(model.IssueClassMismatch, id, key, "%s element in object of type 0x%08x", key.Class(), uint32(description))
+			}
+			if !isKnownElement(hiveObject, key) {
+				report(model.IssueUnknownElement, id, key, "unknown element type")
+			}
+
+			for _, ref := range elementReferences(key, element) {
+				if !exists[strings.ToLower(ref)] && !strings.EqualFold(ref, CurrentObjectId) {
+					report(model.IssueDanglingReference, id, key, "references missing object %s", ref)
+				}
+			}
+		}
+
+		effective, err := EffectiveElements(b, id)
+		if errors.Is(err, ErrInheritanceCycle) {
+			report(model.IssueInheritanceCycle, id, model.ElementInheritedObjects, "%v", err)
+		}
+		if description.ObjectType() == model.ObjectApplication {
+			for _, key := range MandatoryElements[description.ApplicationType()] {
+				if _, ok := effective[key]; ok {
+					continue
+				}
+				if _, ok := hiveObject.Elements[key]; ok {
+					continue
+				}
+				report(model.IssueMissingElement, id, key, "%s requires %s", description.ApplicationType(), elementName(hiveObject, key))
+			}
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b *model.ValidationIssue) int {
+		if c := cmp.Compare(a.ObjectId, b.ObjectId); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Element, b.Element); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Kind, b.Kind)
+	})
+	return issues, nil
+}
+
+// elementReferences returns the object ids an element points at.
+func elementReferences(key model.ElementType, element BcdElement) []string {
+	switch key.Format() {
+	case model.ElementFormatObject:
+		if id, err := element.GetObject(); err == nil {
+			return []string{id}
+		}
+	case model.ElementFormatObjectList:
+		if ids, err := element.GetObjectList(); err == nil {
+			return ids
+		}
+	case model.ElementFormatDevice:
+		// the options object leads the value, so read it even when the
+		// descriptor after it does not parse
+		raw := element.GetRaw()
+		if element.GetType() == RegBinary && len(raw) >= deviceOptionsSize {
+			if options := model.GuidFromBytes(raw[:deviceOptionsSize]); !options.IsZero() {
+				return []string{options.String()}
+			}
+		}
+	}
+	return nil
+}
+
+// classMatches reports whether elements of the class may be stored in an
+// object of the given type. Library elements are valid everywhere.
+func classMatches(description model.BcdDescription, class model.ElementClass) bool {
+	switch class {
+	case model.ElementClassApplication:
+		return description.ObjectType() == model.ObjectApplication ||
+			description.ObjectType() == model.ObjectInherit && description.ObjectSubType() != model.InheritableByDeviceObjects
+	case model.ElementClassDevice:
+		return description.ObjectType() == model.ObjectDevice ||
+			description.ObjectType() == model.ObjectInherit && description.ObjectSubType() != model.InheritableByApplicationObjects
+	}
+	return true
+}
+
+func isKnownElement(object *HiveBcdObject, key model.ElementType) bool {
+	if _, ok := object.ElementTypes()[key.String()]; ok {
+		return true
+	}
+	if key.Class() == model.ElementClassLibrary {
+		if _, ok := model.GenericElementTypes[key.String()]; ok {
+			return true
+		}
+	}
+	return model.FindOptionByType(key, object.Description.ApplicationType()) != nil
+}
+
+func elementName(object *HiveBcdObject, key model.ElementType) string {
+	if option := model.FindOptionByType(key, object.Description.ApplicationType()); option != nil {
+		return option.Name
+	}
+	return key.String()
+}
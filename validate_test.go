@@ -0,0 +1,78 @@
+package go_bcdedit
+
+import (
+	"github.com/jc-lab/go-bcdedit/model"
+	"testing"
+)
+
+// newValidStore returns the test store with a loader that has all its
+// mandatory elements.
+func newValidStore(t *testing.T) Bcdedit {
+	t.Helper()
+	bcd := newTestStore(t)
+	loader := mustGetObject(t, bcd, testLoaderId)
+	device := model.NewBootDevice()
+	mustSet(t)(loader.SetDevice(model.ElementApplicationDevice, device))
+	mustSet(t)(loader.SetString(model.ElementApplicationPath, `\windows\system32\winload.efi`))
+	mustSet(t)(loader.SetDevice(model.ElementOsDevice, device))
+	mustSet(t)(loader.SetString(model.ElementSystemRoot, `\windows`))
+	return bcd
+}
+
+func TestValidateValidStore(t *testing.T) {
+	bcd := newValidStore(t)
+	defer bcd.Close()
+
+	issues, err := bcd.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	bcd := newValidStore(t)
+	defer bcd.Close()
+
+	manager := mustGetObject(t, bcd, "{bootmgr}")
+	loader := mustGetObject(t, bcd, testLoaderId)
+	mustSet(t)(manager.SetObjectList(model.ElementDisplayOrder, []string{testLoaderId, testOtherId}))
+	mustSet(t)(manager.SetElement(model.ElementTimeout, RegSz, []byte{'3', 0, 0, 0}))
+	mustSet(t)(loader.SetElement(model.ElementType(0x35000001), RegBinary, make([]byte, 8)))
+	mustSet(t)(loader.SetElement(model.ElementType(0x2500ffff), RegBinary, make([]byte, 8)))
+	mustSet(t)(loader.SetObjectList(model.ElementInheritedObjects, []string{testLoaderId}))
+	if err := loader.DeleteElement(model.ElementSystemRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := bcd.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.ValidationIssue{
+		{Kind: model.IssueDanglingReference, ObjectId: BootMgrObjectId, Element: model.ElementDisplayOrder.String()},
+		{Kind: model.IssueTypeMismatch, ObjectId: BootMgrObjectId, Element: model.ElementTimeout.String()},
+		{Kind: model.IssueInheritanceCycle, ObjectId: testLoaderId, Element: model.ElementInheritedObjects.String()},
+		{Kind: model.IssueMissingElement, ObjectId: testLoaderId, Element: model.ElementSystemRoot.String()},
+		{Kind: model.IssueUnknownElement, ObjectId: testLoaderId, Element: "2500ffff"},
+		{Kind: model.IssueClassMismatch, ObjectId: testLoaderId, Element: "35000001"},
+	}
+	for _, w := range want {
+		found := false
+		for _, issue := range issues {
+			if issue.Kind == w.Kind && issue.ObjectId == w.ObjectId && issue.Element == w.Element {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing %s issue for %s %s", w.Kind, w.ObjectId, w.Element)
+		}
+	}
+	for i := 1; i < len(issues); i++ {
+		if issues[i-1].ObjectId > issues[i].ObjectId {
+			t.Errorf("issues not sorted by object: %s before %s", issues[i-1].ObjectId, issues[i].ObjectId)
+		}
+	}
+}